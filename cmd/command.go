@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+)
+
+// newFlagSet registers the flags selecting the properties file. A flag wins over its environment
+// variable, which wins over the default.
+func newFlagSet(name string, options *config.LoadOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&options.Profile, "profile", envOrDefault(config.EnvProfile, config.DefaultProfile),
+		"config profile, loads app-<profile>.yml (env "+config.EnvProfile+")")
+	fs.StringVar(&options.Directory, "config-dir", envOrDefault(config.EnvDirectory, config.DefaultDirectory),
+		"directory of the properties files (env "+config.EnvDirectory+")")
+	return fs
+}
+
+// printConfig implements `config print [--redacted=false]`, showing the config after environment
+// overrides. Secrets are hidden unless --redacted=false is given.
+func printConfig(args []string, w io.Writer) error {
+	var options config.LoadOptions
+	fs := newFlagSet("config print", &options)
+	redacted := fs.Bool("redacted", true, "hide passwords, secrets and keys")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	loadConfig, err := config.LoadConfig(options)
+	if err != nil {
+		return err
+	}
+	return loadConfig.Print(w, *redacted)
+}
+
+// runSecrets implements `secrets keygen`, `secrets encrypt -in <yml> -out <file>` and
+// `secrets decrypt -in <file>`. The key is taken from APP_SECRETS_KEY.
+func runSecrets(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: secrets keygen|encrypt|decrypt")
+	}
+	if args[0] == "keygen" {
+		key, err := config.GenerateSecretsKey()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, key)
+		return err
+	}
+
+	fs := flag.NewFlagSet("secrets "+args[0], flag.ExitOnError)
+	in := fs.String("in", "", "input file")
+	out := fs.String("out", "", "output file, stdout when empty")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("-in is required")
+	}
+
+	key, err := config.ParseSecretsKey(os.Getenv(config.EnvSecretsKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.EnvSecretsKey, err)
+	}
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	var result []byte
+	switch args[0] {
+	case "encrypt":
+		result, err = config.EncryptSecrets(key, data)
+	case "decrypt":
+		result, err = config.DecryptSecrets(key, data)
+	default:
+		return fmt.Errorf("unknown secrets command %q", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = w.Write(result)
+		return err
+	}
+	return os.WriteFile(*out, result, 0o600)
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
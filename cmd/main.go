@@ -1,15 +1,31 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/MCPutro/go-management-project/internal/config"
 	"github.com/MCPutro/go-management-project/internal/config/database"
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
 	"github.com/MCPutro/go-management-project/internal/delivery/handler"
 	"github.com/MCPutro/go-management-project/internal/delivery/router"
+	"github.com/MCPutro/go-management-project/internal/health"
+	"github.com/MCPutro/go-management-project/internal/lifecycle"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/middleware"
 	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/internal/tracing"
 	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/migration"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"go.opentelemetry.io/otel"
 )
 
 func main1() {
@@ -39,27 +55,222 @@ func main1() {
 }
 
 func main() {
-	loadConfig, err := config.LoadConfig()
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		if err := printConfig(os.Args[3:], os.Stdout); err != nil {
+			log.Fatalln("failed to print config:", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		if err := runSecrets(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalln("secrets:", err)
+		}
+		return
+	}
+
+	var options config.LoadOptions
+	_ = newFlagSet(os.Args[0], &options).Parse(os.Args[1:])
+
+	loadConfig, err := config.LoadConfig(options)
 	if err != nil {
 		log.Fatalln("failed to load config:", err)
 	}
 
-	postgresDb, err := database.NewPostgresDB(loadConfig.GetDatabaseConfig())
+	logLevel := new(slog.LevelVar)
+	logger, err := applog.NewLogger(loadConfig.GetApplicationConfig(), os.Stdout, logLevel)
+	if err != nil {
+		log.Fatalln("failed to create logger:", err)
+	}
+	// log.Print* dari library lain juga ikut lewat logger ini
+	slog.SetDefault(logger)
+
+	applicationConfig := loadConfig.GetApplicationConfig()
+	lc := lifecycle.New(time.Duration(applicationConfig.ShutdownTimeoutInSecond) * time.Second)
+
+	// propagator tetap dipasang walau tracing mati supaya traceparent diteruskan ke log
+	otel.SetTextMapPropagator(tracing.Propagator())
+	if loadConfig.GetTracingConfig().Enabled {
+		tracerProvider, err := tracing.NewTracerProvider(context.Background(), applicationConfig.Name, loadConfig.GetTracingConfig(), os.Stdout)
+		if err != nil {
+			log.Fatalln("failed to create tracer provider:", err)
+		}
+		otel.SetTracerProvider(tracerProvider)
+		lc.OnShutdown("tracer", tracerProvider.Shutdown)
+	}
+
+	// Ctrl+C saat masih menunggu database harus langsung menghentikan startup
+	startupCtx, stopStartup := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	postgresDb, err := database.NewPostgresDB(startupCtx, loadConfig.GetDatabaseConfig())
+	stopStartup()
 	if err != nil {
 		log.Fatalln("failed to connect to database:", err)
 	}
-	defer postgresDb.Close()
+	lc.OnShutdown("database", func(ctx context.Context) error {
+		return postgresDb.Close()
+	})
+
+	jwtService, err := service.NewJwtService(loadConfig.GetJwtConfig())
+	if err != nil {
+		log.Fatalln("failed to create jwt service:", err)
+	}
+	lc.Go("jwt key rotation", jwtService.RunKeyRotation)
+	lc.Go("jwt key reload", jwtService.RunKeyReload)
+	lc.OnShutdown("workers", lc.StopWorkers)
+
+	actionTokenService, err := service.NewActionTokenService(loadConfig.GetAccountConfig().TokenSecret)
+	if err != nil {
+		log.Fatalln("failed to create action token service:", err)
+	}
+	mailTransport, err := service.NewMailer(loadConfig.GetMailConfig())
+	if err != nil {
+		log.Fatalln("failed to create mailer:", err)
+	}
+	mailer := service.NewMailQueue(mailTransport, 100)
+	lc.Go("mail queue", mailer.Run)
 
 	userRepository := repository.NewUserRepository()
+	refreshTokenRepository := repository.NewRefreshTokenRepository()
+	revokedTokenRepository := repository.NewRevokedTokenRepository()
+	personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository()
+	userIdentityRepository := repository.NewUserIdentityRepository()
+	userActionTokenRepository := repository.NewUserActionTokenRepository()
+	userMFARepository := repository.NewUserMFARepository()
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository()
+	loginAttemptRepository := repository.NewLoginAttemptRepository()
+	auditLogRepository := repository.NewAuditLogRepository()
+	projectRepository := repository.NewProjectRepository()
+	listRepository := repository.NewListRepository()
+	cardRepository := repository.NewCardRepository()
+	rateLimitBucketRepository := repository.NewRateLimitBucketRepository()
+
+	attemptStore := service.NewMemoryAttemptStore()
+	if loadConfig.GetLoginProtectionConfig().Store == "database" {
+		// lockout dibaca dari primary, replica bisa tertinggal dari percobaan yang baru dicatat
+		attemptStore = service.NewDatabaseAttemptStore(postgresDb.Primary(), loginAttemptRepository)
+	}
+	loginThrottle := service.NewLoginThrottle(loadConfig.GetLoginProtectionConfig(), attemptStore)
+
+	auditUsecase := usecase.NewAuditUsecase(postgresDb, auditLogRepository)
 	userUsecase := usecase.NewUserUsecase(postgresDb, userRepository)
+	authUsecase := usecase.NewAuthUsecase(postgresDb, loadConfig.GetJwtConfig(), jwtService,
+		userRepository, refreshTokenRepository, revokedTokenRepository, userIdentityRepository,
+		loadConfig.GetMfaConfig(), actionTokenService, userActionTokenRepository, userMFARepository, recoveryCodeRepository,
+		loginThrottle, auditUsecase)
+	lc.Go("revoked token cleanup", authUsecase.RunRevokedTokenCleanup)
+	personalAccessTokenUsecase := usecase.NewPersonalAccessTokenUsecase(postgresDb, personalAccessTokenRepository, userRepository)
+	lc.Go("personal access token last used", personalAccessTokenUsecase.RunLastUsedFlush)
+	accountUsecase := usecase.NewAccountUsecase(postgresDb, loadConfig.GetAccountConfig(), actionTokenService, mailer,
+		userRepository, userActionTokenRepository, refreshTokenRepository, personalAccessTokenRepository)
+	mfaUsecase := usecase.NewMFAUsecase(postgresDb, loadConfig.GetMfaConfig(), userRepository, userMFARepository, recoveryCodeRepository)
+	projectUsecase := usecase.NewProjectUsecase(postgresDb, projectRepository, listRepository)
+	listUsecase := usecase.NewListUsecase(postgresDb, listRepository)
+	cardUsecase := usecase.NewCardUsecase(postgresDb, cardRepository)
+
 	userHandler := handler.NewUserHandler(userUsecase)
+	authHandler := handler.NewAuthHandler(authUsecase, loadConfig.Runtime())
+	jwksHandler := handler.NewJWKSHandler(jwtService)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenUsecase)
+	accountHandler := handler.NewAccountHandler(accountUsecase)
+	mfaHandler := handler.NewMFAHandler(mfaUsecase)
+	projectHandler := handler.NewProjectHandler(projectUsecase)
+	listHandler := handler.NewListHandler(listUsecase)
+	cardHandler := handler.NewCardHandler(cardUsecase)
+
+	jwtAuth := middleware.JWTAuth(jwtService, authUsecase, personalAccessTokenUsecase)
+	rateLimitStore := service.NewMemoryRateLimitStore()
+	if loadConfig.GetRateLimitConfig().Store == "database" {
+		rateLimitStore = service.NewDatabaseRateLimitStore(postgresDb.Primary(), rateLimitBucketRepository)
+	}
+	rateLimiter := middleware.NewRateLimiter(loadConfig.GetRateLimitConfig(), rateLimitStore)
 
-	app := fiber.New()
+	// setting runtime ikut berubah saat file properties diedit, tanpa restart
+	loadConfig.Runtime().Subscribe(func(runtime config.RuntimeConfig, _ []config.Change) {
+		level, _ := applog.ParseLevel(runtime.LogLevel) // sudah divalidasi saat reload
+		logLevel.Set(level)
+		rateLimiter.Update(&runtime.RateLimit)
+	})
+	loadConfig.Runtime().Subscribe(auditUsecase.ConfigReloaded)
+	loadConfig.WatchRuntime()
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+		// c.IP() dipakai untuk throttle login dan rate limit, jadi header proxy hanya dipercaya dari proxy sendiri
+		ProxyHeader:             applicationConfig.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          applicationConfig.TrustedProxies,
+		EnableIPValidation:      true,
+	})
+	app.Use(middleware.RequestID(), middleware.AccessLog(logger), middleware.Tracing(), middleware.Metrics(), middleware.CORS(loadConfig.Runtime()))
+
+	healthChecker := health.NewHealth(2 * time.Second)
+	healthChecker.Register("database", health.DatabaseCheck(postgresDb.Primary()))
+	healthChecker.Register("migrations", health.MigrationCheck(postgresDb.Primary(), migration.Files))
+	if postgresDb.Replica() != nil {
+		healthChecker.RegisterInformational("database_replica", health.DatabaseCheck(postgresDb.Replica()))
+	}
+	lc.Go("readiness checks", func(ctx context.Context) {
+		healthChecker.Run(ctx, 5*time.Second)
+	})
+	router.RegisterHealthRoutes(app, handler.NewHealthHandler(healthChecker))
+
+	if loadConfig.GetMetricsConfig().Enabled {
+		if err := metrics.RegisterDB(postgresDb.Primary(), "postgres"); err != nil {
+			log.Fatalln("failed to register database metrics:", err)
+		}
+		if postgresDb.Replica() != nil {
+			if err := metrics.RegisterDB(postgresDb.Replica(), "postgres_replica"); err != nil {
+				log.Fatalln("failed to register database metrics:", err)
+			}
+		}
+		// metrics hanya dilayani di port internal, tidak lewat listener publik
+		metricsApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+		router.RegisterMetricsRoutes(metricsApp, loadConfig.GetMetricsConfig().Path, adaptor.HTTPHandler(metrics.Handler()))
+		go func() {
+			if err := metricsApp.Listen(":" + loadConfig.GetMetricsConfig().Port); err != nil {
+				slog.Error("metrics server stopped", slog.Any("error", err))
+			}
+		}()
+		lc.OnShutdown("metrics server", metricsApp.ShutdownWithContext)
+	}
+
+	router.RegisterWellKnownRoutes(app, jwksHandler)
+	// quota per IP untuk semua route /auth, termasuk yang bisa dipanggil tanpa login
+	app.Use("/auth", rateLimiter.IPHandler("auth_ip"))
+	router.RegisterAuthRoutes(app, authHandler, jwtAuth, rateLimiter.Handler("auth"))
+	router.RegisterAccountRoutes(app, accountHandler, jwtAuth, rateLimiter.Handler("auth"))
+	router.RegisterMFARoutes(app, mfaHandler, jwtAuth, rateLimiter.Handler("auth"))
+	router.RegisterPersonalAccessTokenRoutes(app, personalAccessTokenHandler, jwtAuth, rateLimiter.Handler("tokens"))
+
+	if loadConfig.GetOidcConfig().Enabled {
+		oidcService, err := service.NewOIDCService(loadConfig.GetOidcConfig())
+		if err != nil {
+			log.Fatalln("failed to create oidc service:", err)
+		}
+		router.RegisterOIDCRoutes(app, handler.NewOIDCHandler(oidcService, authUsecase), rateLimiter.Handler("auth"))
+	}
+	router.RegisterUserRoutes(app, userHandler, jwtAuth, rateLimiter.Handler("users"))
+	router.RegisterProjectRoutes(app, projectHandler, jwtAuth, rateLimiter.Handler("projects"))
+	router.RegisterListRoutes(app, listHandler, jwtAuth, rateLimiter.Handler("projects"))
+	router.RegisterCardRoutes(app, cardHandler, jwtAuth, rateLimiter.Handler("projects"))
 
-	router.RegisterUserRoutes(app, userHandler)
+	// berhenti menerima koneksi baru lalu tunggu handler yang masih berjalan
+	lc.OnShutdown("http server", app.ShutdownWithContext)
+	lc.OnShutdown("readiness", func(ctx context.Context) error {
+		// readiness gagal lebih dulu supaya load balancer berhenti mengirim request baru
+		healthChecker.MarkShuttingDown()
+		select {
+		case <-time.After(time.Duration(applicationConfig.ShutdownDelayInSecond) * time.Second):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
 
-	err = app.Listen(":" + loadConfig.GetApplicationConfig().Port)
+	err = lc.Run(context.Background(), func() error {
+		return app.Listen(":" + applicationConfig.Port)
+	})
 	if err != nil {
-		log.Fatalln("failed to listen:", err)
+		log.Fatalln("shutdown with error:", err)
 	}
+	slog.Info("server stopped")
 }
@@ -0,0 +1,167 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+// Kind decides the HTTP status of an error; Code is the machine readable value sent to clients.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindTooManyRequests
+	KindUnavailable
+)
+
+const (
+	CodeInternal           = "internal_error"
+	CodeValidation         = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodePreconditionFailed = "precondition_failed"
+	CodeTooManyRequests    = "too_many_requests"
+	CodeUnavailable        = "service_unavailable"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	// Err is the cause; it is logged but never sent to clients.
+	Err error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "Internal server error", Err: err}
+}
+
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: CodeValidation, Message: message, Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, CodeForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, CodeConflict, message)
+}
+
+func PreconditionFailed(message string) *Error {
+	return New(KindPreconditionFailed, CodePreconditionFailed, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(KindTooManyRequests, CodeTooManyRequests, message)
+}
+
+func Unavailable(message string) *Error {
+	return New(KindUnavailable, CodeUnavailable, message)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode returns a copy with a more specific code, e.g. invalid_token instead of unauthorized.
+func (e *Error) WithCode(code string) *Error {
+	copied := *e
+	copied.Code = code
+	return &copied
+}
+
+// Wrap returns a copy that keeps err as cause.
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// From returns err as *Error, translating the sentinel errors of utils. Anything unknown is internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, utils.ErrNotFound):
+		return NotFound("Resource not found").Wrap(err)
+	case errors.Is(err, utils.ErrInvalidInput):
+		return Validation(err.Error()).Wrap(err)
+	case errors.Is(err, utils.ErrInvalidCredentials):
+		return Unauthorized(err.Error()).WithCode("invalid_credentials").Wrap(err)
+	case errors.Is(err, utils.ErrInvalidToken):
+		return Unauthorized(err.Error()).WithCode("invalid_token").Wrap(err)
+	case errors.Is(err, utils.ErrTokenReused):
+		return Unauthorized(err.Error()).WithCode("token_reused").Wrap(err)
+	case errors.Is(err, utils.ErrVersionConflict):
+		return PreconditionFailed("The resource was modified by another request, fetch it again and retry").Wrap(err)
+	case errors.Is(err, utils.ErrTooManyAttempts):
+		return TooManyRequests(err.Error()).Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Unavailable("The request took too long, please try again").Wrap(err)
+	default:
+		return Internal(err)
+	}
+}
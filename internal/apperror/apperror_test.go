@@ -0,0 +1,47 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+func TestFrom(t *testing.T) {
+	conflict := Conflict("Email is already registered")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "application error", err: conflict, wantStatus: http.StatusConflict, wantCode: CodeConflict},
+		{name: "wrapped application error", err: fmt.Errorf("create user: %w", conflict), wantStatus: http.StatusConflict, wantCode: CodeConflict},
+		{name: "not found", err: utils.ErrNotFound, wantStatus: http.StatusNotFound, wantCode: CodeNotFound},
+		{name: "invalid input", err: fmt.Errorf("%w: unknown scope", utils.ErrInvalidInput), wantStatus: http.StatusBadRequest, wantCode: CodeValidation},
+		{name: "invalid token", err: utils.ErrInvalidToken, wantStatus: http.StatusUnauthorized, wantCode: "invalid_token"},
+		{name: "version conflict", err: utils.ErrVersionConflict, wantStatus: http.StatusPreconditionFailed, wantCode: CodePreconditionFailed},
+		{name: "too many attempts", err: utils.ErrTooManyAttempts, wantStatus: http.StatusTooManyRequests, wantCode: CodeTooManyRequests},
+		{name: "unknown error", err: errors.New("pq: connection refused"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status() != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("From() = %d %s, want %d %s", got.Status(), got.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestError_Problem(t *testing.T) {
+	err := Internal(errors.New("pq: password authentication failed"))
+
+	problem := err.Problem("/users")
+	if problem.Detail != "Internal server error" || problem.Status != http.StatusInternalServerError || problem.Instance != "/users" {
+		t.Errorf("Problem() = %+v, the cause must not reach the client", problem)
+	}
+}
@@ -0,0 +1,31 @@
+package apperror
+
+import "net/http"
+
+// Problem is the RFC 7807 body of an error response, extended with code and field errors.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	// RequestID is the X-Request-ID of the failed request, set by the error handler.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+const ProblemContentType = "application/problem+json"
+
+func (e *Error) Problem(instance string) Problem {
+	status := e.Status()
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
-	"github.com/spf13/viper"
+	"io"
+	"strings"
 	"sync"
+
+	"github.com/spf13/viper"
 )
 
 var (
@@ -18,84 +21,378 @@ var (
 
 	jwtConfigOnce sync.Once
 	jwtCfg        JwtConfig
+
+	oidcConfigOnce sync.Once
+	oidcCfg        OidcConfig
+
+	mailConfigOnce sync.Once
+	mailCfg        MailConfig
+
+	accountConfigOnce sync.Once
+	accountCfg        AccountConfig
+
+	mfaConfigOnce sync.Once
+	mfaCfg        MfaConfig
+
+	loginProtectionConfigOnce sync.Once
+	loginProtectionCfg        LoginProtectionConfig
+
+	rateLimitConfigOnce sync.Once
+	rateLimitCfg        RateLimitConfig
+
+	metricsConfigOnce sync.Once
+	metricsCfg        MetricsConfig
+
+	tracingConfigOnce sync.Once
+	tracingCfg        TracingConfig
 )
 
 type Config interface {
 	GetApplicationConfig() *ApplicationConfig
 	GetDatabaseConfig() *DatabaseConfig
 	GetJwtConfig() *JwtConfig
+	GetOidcConfig() *OidcConfig
+	GetMailConfig() *MailConfig
+	GetAccountConfig() *AccountConfig
+	GetMfaConfig() *MfaConfig
+	GetLoginProtectionConfig() *LoginProtectionConfig
+	GetRateLimitConfig() *RateLimitConfig
+	GetMetricsConfig() *MetricsConfig
+	GetTracingConfig() *TracingConfig
+	// Print writes the effective config as YAML; redacted hides every field tagged secret.
+	Print(w io.Writer, redacted bool) error
+	// Runtime returns the settings that can change without a restart.
+	Runtime() *Runtime
+	// WatchRuntime starts reloading Runtime when the properties file changes.
+	WatchRuntime()
 }
 
 type config struct {
 	Application ApplicationConfig `mapstructure:"Application"`
 	Database    DatabaseConfig    `mapstructure:"Database"`
 	Jwt         JwtConfig         `mapstructure:"Jwt"`
+	Oidc        OidcConfig        `mapstructure:"Oidc"`
+	Mail        MailConfig        `mapstructure:"Mail"`
+	Account     AccountConfig     `mapstructure:"Account"`
+	Mfa         MfaConfig         `mapstructure:"Mfa"`
+
+	LoginProtection LoginProtectionConfig `mapstructure:"LoginProtection"`
+	RateLimit       RateLimitConfig       `mapstructure:"RateLimit"`
+	Metrics         MetricsConfig         `mapstructure:"Metrics"`
+	Tracing         TracingConfig         `mapstructure:"Tracing"`
+	Cors            CorsConfig            `mapstructure:"Cors"`
+	Secrets         SecretsConfig         `mapstructure:"Secrets"`
+	// Features are runtime feature flags, keyed by the lowercase flag name.
+	Features map[string]bool `mapstructure:"Features"`
+
+	viper   *viper.Viper
+	runtime *Runtime
 }
 
 type ApplicationConfig struct {
-	Name string `mapstructure:"Name"`
-	Port string `mapstructure:"Port"`
+	Name string    `mapstructure:"Name" validate:"required"`
+	Port string    `mapstructure:"Port" validate:"required,numeric"`
+	Log  LogConfig `mapstructure:"Log"`
+	// ShutdownTimeoutInSecond bounds draining requests and workers and closing the database (default 30).
+	ShutdownTimeoutInSecond int `mapstructure:"ShutdownTimeoutInSecond" validate:"gte=0"`
+	// ShutdownDelayInSecond keeps serving after /readyz turned not ready, so load balancers
+	// stop routing to the instance before it stops accepting connections.
+	ShutdownDelayInSecond int `mapstructure:"ShutdownDelayInSecond" validate:"gte=0"`
+	// ProxyHeader (e.g. X-Forwarded-For) is only read on requests coming from TrustedProxies; other
+	// requests use the address of the connection. Fiber takes the first address of the header, so the
+	// trusted proxy must overwrite the header instead of appending to what the client sent.
+	ProxyHeader string `mapstructure:"ProxyHeader"`
+	// TrustedProxies lists the addresses or CIDR ranges of the load balancers in front of the app.
+	TrustedProxies []string `mapstructure:"TrustedProxies" validate:"dive,ip|cidr"`
+}
+
+type LogConfig struct {
+	// Level: debug, info, warn atau error (default info)
+	Level string `mapstructure:"Level" validate:"omitempty,oneof=debug info warn warning error"`
+	// Format: json atau text (default json)
+	Format string `mapstructure:"Format" validate:"omitempty,oneof=json text"`
 }
 
 type DatabaseConfig struct {
 	PostgresSql PostgresConfig `mapstructure:"PostgresSQL"`
-	MySql       MySqlConfig    `mapstructure:"MySQL"`
+	// PostgresSqlReplica receives the ReadOnly transactions when set; reads fall back to the
+	// primary while the replica is unreachable.
+	PostgresSqlReplica *PostgresConfig `mapstructure:"PostgresSQLReplica" validate:"omitnil"`
+	MySql              MySqlConfig     `mapstructure:"MySQL"`
+	// ConnectRetry paces the connection attempts at startup.
+	ConnectRetry BackoffConfig `mapstructure:"ConnectRetry"`
+	// TransactionRetry paces running a transaction again after a serialization failure or deadlock,
+	// see database.DB.RunInTx for the isolation levels it is meant for.
+	TransactionRetry BackoffConfig `mapstructure:"TransactionRetry"`
 }
 
+// PostgresConfig holds the connection settings that DSN assembles into a keyword/value string.
 type PostgresConfig struct {
-	Name         string `mapstructure:"Name"`
-	Host         string `mapstructure:"Host"`
-	Port         string `mapstructure:"Port"`
-	DatabaseName string `mapstructure:"DatabaseName"`
-	Username     string `mapstructure:"Username"`
-	Password     string `mapstructure:"Password"`
-	FormatDSN    string `mapstructure:"FormatDSN"`
-	DSN          string
+	Name         string `mapstructure:"Name" validate:"required"`
+	Host         string `mapstructure:"Host" validate:"required"`
+	Port         string `mapstructure:"Port" validate:"required,numeric"`
+	DatabaseName string `mapstructure:"DatabaseName" validate:"required"`
+	Username     string `mapstructure:"Username" validate:"required"`
+	Password     string `mapstructure:"Password" secret:"true"`
+	SSLMode      string `mapstructure:"SSLMode" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"`
+	// SSLRootCert, SSLCert dan SSLKey adalah path file PEM
+	SSLRootCert string `mapstructure:"SSLRootCert"`
+	SSLCert     string `mapstructure:"SSLCert"`
+	SSLKey      string `mapstructure:"SSLKey"`
+	TimeZone    string `mapstructure:"TimeZone"`
+
+	ConnectTimeoutInSecond   int `mapstructure:"ConnectTimeoutInSecond" validate:"gte=0"`
+	StatementTimeoutInSecond int `mapstructure:"StatementTimeoutInSecond" validate:"gte=0"`
+	// Parameters are passed as they are, e.g. application_name or search_path.
+	Parameters []DSNParameter `mapstructure:"Parameters" validate:"dive"`
+	Pool       PoolConfig     `mapstructure:"Pool"`
+}
+
+// DSNParameter is an extra connection parameter. Parameters are a list instead of a map because
+// viper lowercases map keys, while MySQL parameters such as parseTime are case sensitive.
+type DSNParameter struct {
+	Name  string `mapstructure:"Name" validate:"required"`
+	Value string `mapstructure:"Value"`
 }
 
+// MySqlConfig holds the connection settings that DSN assembles into a go-sql-driver/mysql DSN.
 type MySqlConfig struct {
 	Name         string `mapstructure:"Name"`
 	Host         string `mapstructure:"Host"`
-	Port         string `mapstructure:"Port"`
+	Port         string `mapstructure:"Port" validate:"omitempty,numeric"`
 	DatabaseName string `mapstructure:"DatabaseName"`
 	Username     string `mapstructure:"Username"`
-	Password     string `mapstructure:"Password"`
-	FormatDNS    string `mapstructure:"FormatDNS"`
-	DNS          string
+	Password     string `mapstructure:"Password" secret:"true"`
+	// TLS: true, false, skip-verify, preferred atau nama config yang diregister ke driver
+	TLS      string `mapstructure:"TLS"`
+	TimeZone string `mapstructure:"TimeZone"`
+
+	TimeoutInSecond      int `mapstructure:"TimeoutInSecond" validate:"gte=0"`
+	ReadTimeoutInSecond  int `mapstructure:"ReadTimeoutInSecond" validate:"gte=0"`
+	WriteTimeoutInSecond int `mapstructure:"WriteTimeoutInSecond" validate:"gte=0"`
+	// Parameters are passed as they are, e.g. parseTime or multiStatements.
+	Parameters []DSNParameter `mapstructure:"Parameters" validate:"dive"`
+	Pool       PoolConfig     `mapstructure:"Pool"`
+}
+
+// BackoffConfig is an exponential backoff: the n-th retry waits InitialInterval * Multiplier^(n-1),
+// capped at MaxInterval, of which a random Jitter fraction is taken off. Zero values use the defaults
+// of the caller; Jitter is only left at the default when it is missing, so 0 turns jitter off.
+type BackoffConfig struct {
+	MaxAttempts                  int      `mapstructure:"MaxAttempts" validate:"gte=0"`
+	InitialIntervalInMillisecond int      `mapstructure:"InitialIntervalInMillisecond" validate:"gte=0"`
+	MaxIntervalInMillisecond     int      `mapstructure:"MaxIntervalInMillisecond" validate:"gte=0"`
+	Multiplier                   float64  `mapstructure:"Multiplier" validate:"gte=0"`
+	Jitter                       *float64 `mapstructure:"Jitter" validate:"omitempty,gte=0,lte=1"`
+}
+
+// PoolConfig sizes the database/sql connection pool; zero values fall back to the defaults of NewPostgresDB.
+type PoolConfig struct {
+	MaxOpenConns            int `mapstructure:"MaxOpenConns" validate:"gte=0"`
+	MaxIdleConns            int `mapstructure:"MaxIdleConns" validate:"gte=0"`
+	ConnMaxLifetimeInSecond int `mapstructure:"ConnMaxLifetimeInSecond" validate:"gte=0"`
+	ConnMaxIdleTimeInSecond int `mapstructure:"ConnMaxIdleTimeInSecond" validate:"gte=0"`
 }
 
 type JwtConfig struct {
-	SecretKey          string `mapstructure:"SecretKey"`
-	ExpirationInSecond int    `mapstructure:"ExpirationInSecond"`
+	SecretKey          string `mapstructure:"SecretKey" secret:"true"`
+	ExpirationInSecond int    `mapstructure:"ExpirationInSecond" validate:"gt=0"`
+	// RefreshExpirationInSecond must outlive the access token, otherwise a session cannot be refreshed.
+	RefreshExpirationInSecond int            `mapstructure:"RefreshExpirationInSecond" validate:"gt=0,gtfield=ExpirationInSecond"`
+	Issuer                    string         `mapstructure:"Issuer" validate:"required"`
+	Audience                  []string       `mapstructure:"Audience"`
+	SigningKeyID              string         `mapstructure:"SigningKeyID"`
+	Keys                      []JwtKeyConfig `mapstructure:"Keys"`
+	// KeyDirectory holds generated <kid>.pem private keys so rotated keys survive restarts. Instances
+	// sharing it reload it every KeyReloadIntervalInSecond and when a token names an unknown kid.
+	KeyDirectory              string            `mapstructure:"KeyDirectory"`
+	KeyReloadIntervalInSecond int               `mapstructure:"KeyReloadIntervalInSecond" validate:"gte=0"`
+	Rotation                  JwtRotationConfig `mapstructure:"Rotation"`
+}
+
+// JwtKeyConfig describes one key of the JWT key set. Keys without a private key can only verify,
+// which keeps tokens signed by a retired key valid until they expire.
+type JwtKeyConfig struct {
+	ID             string `mapstructure:"ID"`
+	Algorithm      string `mapstructure:"Algorithm"` // HS256, RS256 atau EdDSA
+	Secret         string `mapstructure:"Secret" secret:"true"`
+	PrivateKey     string `mapstructure:"PrivateKey" secret:"true"` // PEM
+	PublicKey      string `mapstructure:"PublicKey"`                // PEM
+	PrivateKeyFile string `mapstructure:"PrivateKeyFile"`
+	PublicKeyFile  string `mapstructure:"PublicKeyFile"`
+}
+
+// JwtRotationConfig generates a new signing key on a schedule. When several instances share the
+// KeyDirectory, enable it on exactly one of them; the others pick its keys up by reloading.
+type JwtRotationConfig struct {
+	Enabled          bool   `mapstructure:"Enabled"`
+	Algorithm        string `mapstructure:"Algorithm"` // RS256 atau EdDSA
+	IntervalInSecond int    `mapstructure:"IntervalInSecond" validate:"required_if=Enabled true,gte=0"`
+	// OverlapInSecond is how long a replaced key still verifies tokens; never shorter than the token lifetime.
+	OverlapInSecond int `mapstructure:"OverlapInSecond"`
+}
+
+type OidcConfig struct {
+	Enabled      bool     `mapstructure:"Enabled"`
+	ProviderName string   `mapstructure:"ProviderName"`
+	IssuerURL    string   `mapstructure:"IssuerURL" validate:"required_if=Enabled true,omitempty,url"`
+	ClientID     string   `mapstructure:"ClientID" validate:"required_if=Enabled true"`
+	ClientSecret string   `mapstructure:"ClientSecret" secret:"true"`
+	RedirectURL  string   `mapstructure:"RedirectURL" validate:"required_if=Enabled true,omitempty,url"`
+	Scopes       []string `mapstructure:"Scopes"`
+	// AutoProvision creates a local user on the first login of an unknown identity.
+	AutoProvision bool `mapstructure:"AutoProvision"`
+	// StateSecret signs the cookie that carries state, nonce and PKCE verifier between login and callback.
+	StateSecret      string `mapstructure:"StateSecret" validate:"required_if=Enabled true" secret:"true"`
+	StateTTLInSecond int    `mapstructure:"StateTTLInSecond"`
+}
+
+type MailConfig struct {
+	Driver string `mapstructure:"Driver" validate:"omitempty,oneof=smtp file log"` // smtp, file atau log
+	From   string `mapstructure:"From"`
+	// FilePath is where the file driver appends outgoing messages.
+	FilePath string     `mapstructure:"FilePath"`
+	Smtp     SmtpConfig `mapstructure:"Smtp"`
+}
+
+type SmtpConfig struct {
+	Host     string `mapstructure:"Host"`
+	Port     string `mapstructure:"Port"`
+	Username string `mapstructure:"Username"`
+	Password string `mapstructure:"Password" secret:"true"`
+}
+
+type AccountConfig struct {
+	// TokenSecret signs email verification and password reset tokens.
+	TokenSecret                         string `mapstructure:"TokenSecret" validate:"required" secret:"true"`
+	EmailVerificationExpirationInSecond int    `mapstructure:"EmailVerificationExpirationInSecond"`
+	PasswordResetExpirationInSecond     int    `mapstructure:"PasswordResetExpirationInSecond"`
+	// URL yang dikirim lewat email, %s diganti dengan token
+	EmailVerificationURL string `mapstructure:"EmailVerificationURL"`
+	PasswordResetURL     string `mapstructure:"PasswordResetURL"`
+}
+
+type MfaConfig struct {
+	// Issuer is the account label shown by authenticator apps.
+	Issuer string `mapstructure:"Issuer"`
+	// ChallengeExpirationInSecond is how long the user has to enter the TOTP code after the password.
+	ChallengeExpirationInSecond int `mapstructure:"ChallengeExpirationInSecond"`
+	// MaxChallengeAttempts wrong codes void the challenge; the user has to enter the password again.
+	MaxChallengeAttempts int `mapstructure:"MaxChallengeAttempts"`
+}
+
+type LoginProtectionConfig struct {
+	Store          string `mapstructure:"Store" validate:"omitempty,oneof=memory database"` // memory atau database
+	WindowInSecond int    `mapstructure:"WindowInSecond"`
+	// FreeAttempts failures of an account are allowed before each further failure adds a delay,
+	// doubling from BaseDelayInSecond up to MaxDelayInSecond.
+	FreeAttempts       int `mapstructure:"FreeAttempts"`
+	BaseDelayInSecond  int `mapstructure:"BaseDelayInSecond"`
+	MaxDelayInSecond   int `mapstructure:"MaxDelayInSecond"`
+	AccountMaxAttempts int `mapstructure:"AccountMaxAttempts"`
+	IPMaxAttempts      int `mapstructure:"IPMaxAttempts"`
+	LockoutInSecond    int `mapstructure:"LockoutInSecond"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"Enabled"`
+	// Store is read once at startup: memory limits each instance on its own, database shares the
+	// buckets between instances.
+	Store string `mapstructure:"Store" validate:"omitempty,oneof=memory database"`
+	// FailOpen lets requests through while the store is failing; otherwise they get a 503.
+	FailOpen bool                `mapstructure:"FailOpen"`
+	Default  RateLimitRuleConfig `mapstructure:"Default"`
+	// Groups overrides Default per route group, keyed by the lowercase group name (auth, users, ...).
+	Groups map[string]RateLimitRuleConfig `mapstructure:"Groups"`
+}
+
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"Enabled"`
+	// Path tempat Prometheus melakukan scrape, default /metrics
+	Path string `mapstructure:"Path" validate:"omitempty,startswith=/"`
+	// Port of the internal listener serving Path. It is separate from Application.Port so the
+	// metrics are not reachable through the public load balancer.
+	Port string `mapstructure:"Port" validate:"required_if=Enabled true,omitempty,numeric"`
+}
+
+type TracingConfig struct {
+	Enabled  bool   `mapstructure:"Enabled"`
+	Exporter string `mapstructure:"Exporter" validate:"omitempty,oneof=otlp stdout"` // otlp atau stdout
+	// Endpoint is the host:port of the OTLP/HTTP collector, e.g. localhost:4318.
+	Endpoint string `mapstructure:"Endpoint"`
+	Insecure bool   `mapstructure:"Insecure"`
+	// SampleRatio is the fraction of new traces that are sampled; 0 samples all of them.
+	SampleRatio float64 `mapstructure:"SampleRatio" validate:"gte=0,lte=1"`
+}
+
+// SecretsConfig points to the AES-GCM encrypted secrets file referenced by ${secret:<name>} values.
+// Its key is read from APP_SECRETS_KEY.
+type SecretsConfig struct {
+	File string `mapstructure:"File"`
+}
+
+type CorsConfig struct {
+	// AllowOrigins lists the origins allowed to call the API from a browser; * allows all of them.
+	AllowOrigins []string `mapstructure:"AllowOrigins"`
+}
+
+// RateLimitRuleConfig is a token bucket holding Limit requests that refills completely every PeriodInSecond.
+type RateLimitRuleConfig struct {
+	Limit          int `mapstructure:"Limit"`
+	PeriodInSecond int `mapstructure:"PeriodInSecond"`
 }
 
-func LoadConfig() (Config, error) {
+// LoadOptions selects the properties file <Directory>/app-<Profile>.yml.
+type LoadOptions struct {
+	Profile   string
+	Directory string
+}
+
+// LoadConfig reads the properties file of the profile, applies the APP_* environment overrides
+// (e.g. APP_DATABASE_POSTGRESSQL_PASSWORD for Database.PostgresSQL.Password) and validates the result.
+// Any string value may be ${file:<path>}, read from a file such as a Docker or Kubernetes secret
+// mount, or ${secret:<name>}, looked up in the encrypted Secrets.File.
+func LoadConfig(options LoadOptions) (Config, error) {
+	if options.Profile == "" {
+		options.Profile = DefaultProfile
+	}
+	if options.Directory == "" {
+		options.Directory = DefaultDirectory
+	}
+
 	v := viper.New()
-	v.SetConfigName("app-2-local") // nama file tanpa .yml
+	v.SetConfigName("app-" + options.Profile) // nama file tanpa .yml
 	v.SetConfigType("yaml")
-	v.AddConfigPath("./properties") // lokasi folder properties
+	v.AddConfigPath(options.Directory)
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	// AutomaticEnv hanya berlaku untuk key yang ada di file, key lain harus di-bind manual
+	if err := bindEnvs(v); err != nil {
+		return nil, err
+	}
 
 	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+		return nil, fmt.Errorf("error reading config file of profile %q: %w", options.Profile, err)
 	}
 
-	if err := v.Unmarshal(&cfg); err != nil {
+	var loaded config
+	if err := v.Unmarshal(&loaded); err != nil {
 		return nil, fmt.Errorf("error unmarshalling config: %w", err)
 	}
 
-	var dsn string
-	// Construct DSN for Postgres
-	dsn = fmt.Sprintf(cfg.Database.PostgresSql.FormatDSN,
-		cfg.Database.PostgresSql.Username, cfg.Database.PostgresSql.Password,
-		cfg.Database.PostgresSql.Host, cfg.Database.PostgresSql.Port, cfg.Database.PostgresSql.DatabaseName)
-	cfg.Database.PostgresSql.DSN = dsn
+	if err := resolveSecrets(&loaded); err != nil {
+		return nil, err
+	}
+	if err := validateConfig(&loaded); err != nil {
+		return nil, err
+	}
 
-	// Construct DNS for MySQL
-	dsn = fmt.Sprintf(cfg.Database.MySql.FormatDNS,
-		cfg.Database.MySql.Username, cfg.Database.MySql.Password,
-		cfg.Database.MySql.Host, cfg.Database.MySql.Port, cfg.Database.MySql.DatabaseName)
-	cfg.Database.MySql.DNS = dsn
+	loaded.viper = v
+	loaded.runtime = newRuntime(&loaded)
 
+	cfg = loaded
 	return &cfg, nil
 }
 
@@ -120,3 +417,65 @@ func (c *config) GetJwtConfig() *JwtConfig {
 	})
 	return &jwtCfg
 }
+
+func (c *config) GetOidcConfig() *OidcConfig {
+	oidcConfigOnce.Do(func() {
+		oidcCfg = c.Oidc
+	})
+	return &oidcCfg
+}
+
+func (c *config) GetMailConfig() *MailConfig {
+	mailConfigOnce.Do(func() {
+		mailCfg = c.Mail
+	})
+	return &mailCfg
+}
+
+func (c *config) GetAccountConfig() *AccountConfig {
+	accountConfigOnce.Do(func() {
+		accountCfg = c.Account
+	})
+	return &accountCfg
+}
+
+func (c *config) GetMfaConfig() *MfaConfig {
+	mfaConfigOnce.Do(func() {
+		mfaCfg = c.Mfa
+		if mfaCfg.MaxChallengeAttempts <= 0 {
+			mfaCfg.MaxChallengeAttempts = 5
+		}
+	})
+	return &mfaCfg
+}
+
+func (c *config) GetLoginProtectionConfig() *LoginProtectionConfig {
+	loginProtectionConfigOnce.Do(func() {
+		loginProtectionCfg = c.LoginProtection
+	})
+	return &loginProtectionCfg
+}
+
+func (c *config) GetRateLimitConfig() *RateLimitConfig {
+	rateLimitConfigOnce.Do(func() {
+		rateLimitCfg = c.RateLimit
+	})
+	return &rateLimitCfg
+}
+
+func (c *config) GetMetricsConfig() *MetricsConfig {
+	metricsConfigOnce.Do(func() {
+		metricsCfg = c.Metrics
+		if metricsCfg.Path == "" {
+			metricsCfg.Path = "/metrics"
+		}
+	})
+	return &metricsCfg
+}
+
+func (c *config) GetTracingConfig() *TracingConfig {
+	tracingConfigOnce.Do(func() {
+		tracingCfg = c.Tracing
+	})
+	return &tracingCfg
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testProperties = `
+Application:
+  Name: test
+  Port: 9999
+Database:
+  PostgresSQL:
+    Name: postgres
+    Host: localhost
+    Port: 5432
+    DatabaseName: test
+    Username: postgres
+    Password: welcome1
+Jwt:
+  ExpirationInSecond: 3600
+  RefreshExpirationInSecond: 86400
+  Issuer: test
+Account:
+  TokenSecret: account-secret
+`
+
+func writeProperties(t *testing.T, profile, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app-"+profile+".yml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := writeProperties(t, "test", testProperties)
+
+	tests := []struct {
+		name    string
+		profile string
+		env     map[string]string
+		wantErr string
+		check   func(t *testing.T, c *config)
+	}{
+		{
+			name:    "file only",
+			profile: "test",
+			check: func(t *testing.T, c *config) {
+				if c.Database.PostgresSql.DSN() != "host=localhost port=5432 user=postgres password=welcome1 dbname=test" {
+					t.Errorf("DSN() = %q", c.Database.PostgresSql.DSN())
+				}
+			},
+		},
+		{
+			name:    "env overrides a key from the file",
+			profile: "test",
+			env:     map[string]string{"APP_DATABASE_POSTGRESSQL_PASSWORD": "from-env"},
+			check: func(t *testing.T, c *config) {
+				if c.Database.PostgresSql.Password != "from-env" || !strings.Contains(c.Database.PostgresSql.DSN(), "password=from-env") {
+					t.Errorf("Password = %q, DSN() = %q, want the env value", c.Database.PostgresSql.Password, c.Database.PostgresSql.DSN())
+				}
+			},
+		},
+		{
+			name:    "env sets a key missing in the file",
+			profile: "test",
+			env:     map[string]string{"APP_OIDC_CLIENTSECRET": "oidc-secret", "APP_JWT_AUDIENCE": "web,mobile"},
+			check: func(t *testing.T, c *config) {
+				if c.Oidc.ClientSecret != "oidc-secret" || len(c.Jwt.Audience) != 2 {
+					t.Errorf("ClientSecret = %q, Audience = %v", c.Oidc.ClientSecret, c.Jwt.Audience)
+				}
+			},
+		},
+		{
+			name:    "invalid values are reported with their env variable",
+			profile: "test",
+			env:     map[string]string{"APP_APPLICATION_PORT": "http", "APP_OIDC_ENABLED": "true"},
+			wantErr: "Application.Port must be a number (APP_APPLICATION_PORT)",
+		},
+		{
+			name:    "refresh token must outlive the access token",
+			profile: "test",
+			env:     map[string]string{"APP_JWT_REFRESHEXPIRATIONINSECOND": "60"},
+			wantErr: "Jwt.RefreshExpirationInSecond must be greater than ExpirationInSecond (APP_JWT_REFRESHEXPIRATIONINSECOND)",
+		},
+		{
+			name:    "missing refresh token expiry",
+			profile: "test",
+			env:     map[string]string{"APP_JWT_REFRESHEXPIRATIONINSECOND": "0"},
+			wantErr: "Jwt.RefreshExpirationInSecond must be greater than 0",
+		},
+		{
+			name:    "unknown profile",
+			profile: "prod",
+			wantErr: `profile "prod"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			loaded, err := LoadConfig(LoadOptions{Profile: tt.profile, Directory: dir})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, loaded.(*config))
+		})
+	}
+}
+
+func TestLoadConfig_DSNParameters(t *testing.T) {
+	properties := strings.Replace(testProperties, "    Password: welcome1\n", `    Password: welcome1
+    Parameters:
+      - Name: application_name
+        Value: go-management
+  MySQL:
+    Host: localhost
+    Port: 3306
+    Parameters:
+      - Name: parseTime
+        Value: "true"
+      - Name: multiStatements
+        Value: "true"
+`, 1)
+
+	loaded, err := LoadConfig(LoadOptions{Profile: "test", Directory: writeProperties(t, "test", properties)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	database := loaded.(*config).Database
+
+	// nama parameter MySQL case sensitive, viper tidak boleh mengubahnya menjadi huruf kecil
+	if dsn := database.MySql.DSN(); !strings.Contains(dsn, "multiStatements=true&parseTime=true") {
+		t.Errorf("MySQL DSN() = %q, want the parameters with their original case", dsn)
+	}
+	if dsn := database.PostgresSql.DSN(); !strings.HasSuffix(dsn, "application_name=go-management") {
+		t.Errorf("PostgreSQL DSN() = %q, want the application_name parameter", dsn)
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	loaded, err := LoadConfig(LoadOptions{Profile: "test", Directory: writeProperties(t, "test", testProperties)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		redacted bool
+		want     string
+		notWant  string
+	}{
+		{name: "redacted", redacted: true, want: "Password: '******'", notWant: "welcome1"},
+		{name: "plain", redacted: false, want: "Password: welcome1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := loaded.Print(&buf, tt.redacted); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if !strings.Contains(out, tt.want) || (tt.notWant != "" && strings.Contains(out, tt.notWant)) {
+				t.Errorf("Print() =\n%s\nwant %q without %q", out, tt.want, tt.notWant)
+			}
+		})
+	}
+}
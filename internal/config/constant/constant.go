@@ -1,5 +1,7 @@
 package constant
 
 const (
-	UserIDKey = "user_id"
+	UserIDKey    = "user_id"
+	PrincipalKey = "principal"
+	RequestIDKey = "request_id"
 )
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+)
+
+// Backoff computes exponentially growing retry delays with jitter, so instances that failed at
+// the same moment do not retry in lockstep.
+type Backoff struct {
+	MaxAttempts int
+	Initial     time.Duration
+	Max         time.Duration
+	Multiplier  float64
+	// Jitter is the fraction of each delay that is randomized, 0 (none) to 1 (full jitter).
+	Jitter float64
+}
+
+var (
+	defaultConnectBackoff     = Backoff{MaxAttempts: 8, Initial: time.Second, Max: 30 * time.Second, Multiplier: 2, Jitter: 0.2}
+	defaultTransactionBackoff = Backoff{MaxAttempts: 3, Initial: 20 * time.Millisecond, Max: 500 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+)
+
+// newBackoff applies cfg over defaults; zero values and a missing Jitter keep the default.
+func newBackoff(cfg config.BackoffConfig, defaults Backoff) Backoff {
+	b := defaults
+	if cfg.MaxAttempts > 0 {
+		b.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialIntervalInMillisecond > 0 {
+		b.Initial = time.Duration(cfg.InitialIntervalInMillisecond) * time.Millisecond
+	}
+	if cfg.MaxIntervalInMillisecond > 0 {
+		b.Max = time.Duration(cfg.MaxIntervalInMillisecond) * time.Millisecond
+	}
+	if cfg.Multiplier > 0 {
+		b.Multiplier = cfg.Multiplier
+	}
+	if cfg.Jitter != nil {
+		b.Jitter = *cfg.Jitter
+	}
+	return b
+}
+
+// Delay returns how long to wait after the given failed attempt, starting at 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := float64(b.Initial) * math.Pow(b.Multiplier, float64(attempt-1))
+	if delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	// ambil sebagian acak dari delay, sisanya tetap supaya retry tidak terlalu rapat
+	delay -= delay * b.Jitter * rand.Float64()
+	return time.Duration(delay)
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{MaxAttempts: 5, Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2, Jitter: 0.5}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 10, want: time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			got := b.Delay(tt.attempt)
+			if got > tt.want || got < tt.want/2 {
+				t.Fatalf("Delay(%d) = %v, want between %v and %v", tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+}
+
+func TestBackoff_DelayWithoutJitter(t *testing.T) {
+	b := Backoff{Initial: 10 * time.Millisecond, Max: time.Second, Multiplier: 3}
+	if got := b.Delay(3); got != 90*time.Millisecond {
+		t.Errorf("Delay(3) = %v, want 90ms", got)
+	}
+}
+
+func TestNewBackoff(t *testing.T) {
+	jitter := func(j float64) *float64 { return &j }
+
+	tests := []struct {
+		name   string
+		config config.BackoffConfig
+		want   func(b *Backoff)
+	}{
+		{name: "defaults", want: func(b *Backoff) {}},
+		{
+			name:   "overrides",
+			config: config.BackoffConfig{MaxAttempts: 2, MaxIntervalInMillisecond: 250},
+			want: func(b *Backoff) {
+				b.MaxAttempts = 2
+				b.Max = 250 * time.Millisecond
+			},
+		},
+		{name: "jitter off", config: config.BackoffConfig{Jitter: jitter(0)}, want: func(b *Backoff) { b.Jitter = 0 }},
+		{name: "jitter", config: config.BackoffConfig{Jitter: jitter(1)}, want: func(b *Backoff) { b.Jitter = 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := defaultTransactionBackoff
+			tt.want(&want)
+			if got := newBackoff(tt.config, defaultTransactionBackoff); got != want {
+				t.Errorf("newBackoff() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSleep_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("sleep() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,129 @@
+// Package databasetest provides a database.DB backed by an in-memory driver, for testing usecases
+// against fake repositories. The driver only supports transactions; statements fail.
+package databasetest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config/database"
+)
+
+// Stats counts the transactions started on a test database.
+type Stats struct {
+	mu        sync.Mutex
+	begins    int
+	readOnly  int
+	commits   int
+	rollbacks int
+}
+
+func (s *Stats) Begins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.begins
+}
+
+// ReadOnly returns how many of the started transactions were read-only.
+func (s *Stats) ReadOnly() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readOnly
+}
+
+func (s *Stats) Commits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commits
+}
+
+func (s *Stats) Rollbacks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rollbacks
+}
+
+// New returns a database without a replica whose conflicting transactions are retried up to three
+// times without a noticeable delay.
+func New() (*database.DB, *Stats) {
+	stats := &Stats{}
+	db := sql.OpenDB(connector{stats: stats})
+	return database.NewDB(db, nil, database.Backoff{MaxAttempts: 3, Initial: time.Millisecond, Max: time.Millisecond, Multiplier: 1}), stats
+}
+
+// NewWithReplica is New with a read replica; each pool counts its own transactions.
+func NewWithReplica() (db *database.DB, primary, replica *Stats) {
+	primary, replica = &Stats{}, &Stats{}
+	db = database.NewDB(sql.OpenDB(connector{stats: primary}), sql.OpenDB(connector{stats: replica}),
+		database.Backoff{MaxAttempts: 3, Initial: time.Millisecond, Max: time.Millisecond, Multiplier: 1})
+	return db, primary, replica
+}
+
+var errNotSupported = errors.New("databasetest: statements are not supported")
+
+type connector struct {
+	stats *Stats
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn(c), nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errNotSupported
+}
+
+type conn struct {
+	stats *Stats
+}
+
+func (c conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errNotSupported
+}
+
+func (c conn) Close() error {
+	return nil
+}
+
+func (c conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c conn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	c.stats.begins++
+	if opts.ReadOnly {
+		c.stats.readOnly++
+	}
+	return tx(c), nil
+}
+
+type tx struct {
+	stats *Stats
+}
+
+func (t tx) Commit() error {
+	t.stats.mu.Lock()
+	defer t.stats.mu.Unlock()
+	t.stats.commits++
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.stats.mu.Lock()
+	defer t.stats.mu.Unlock()
+	t.stats.rollbacks++
+	return nil
+}
@@ -1,42 +1,97 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/tracing"
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-func NewPostgresDB(config *config.DatabaseConfig) (*sql.DB, error) {
-	var db *sql.DB
-	var err error
+// NewPostgresDB connects to the primary, retrying with backoff until it answers, ctx is done or
+// Database.ConnectRetry.MaxAttempts is reached. A configured read replica is opened as well; it
+// may still be down at startup since reads fall back to the primary.
+func NewPostgresDB(ctx context.Context, config *config.DatabaseConfig) (*DB, error) {
+	primary, err := openPostgres(config.PostgresSql)
+	if err != nil {
+		return nil, err
+	}
 
-	for i := 0; i < 5; i++ {
-		db, err = sql.Open(config.PostgresSql.Name, config.PostgresSql.DSN)
-		if err != nil {
-			return nil, err
+	connectBackoff := newBackoff(config.ConnectRetry, defaultConnectBackoff)
+	for attempt := 1; ; attempt++ {
+		err = primary.PingContext(ctx)
+		if err == nil {
+			break
+		}
+		if attempt >= connectBackoff.MaxAttempts || ctx.Err() != nil {
+			primary.Close()
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
 		}
 
-		// test connection
-		err = db.Ping()
+		delay := connectBackoff.Delay(attempt)
+		slog.Warn("failed to connect to database, retrying",
+			slog.Int("attempt", attempt), slog.Duration("retry_in", delay), slog.Any("error", err))
+		if err := sleep(ctx, delay); err != nil {
+			primary.Close()
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+	}
+	slog.Info("successfully connected to database")
+
+	db := &DB{primary: primary, txBackoff: newBackoff(config.TransactionRetry, defaultTransactionBackoff)}
+	if config.PostgresSqlReplica != nil {
+		db.replica, err = openPostgres(*config.PostgresSqlReplica)
 		if err != nil {
-			log.Println("failed to connect to database, retry in 5 seconds")
-			time.Sleep(5 * time.Second)
+			primary.Close()
+			return nil, err
+		}
+		if err := db.replica.PingContext(ctx); err != nil {
+			slog.Warn("read replica is not reachable, reads use the primary until it is", slog.Any("error", err))
 		} else {
-			db.SetMaxIdleConns(5)
-			db.SetMaxOpenConns(100)
-			db.SetConnMaxLifetime(60 * time.Minute)
-			db.SetConnMaxIdleTime(10 * time.Minute)
+			slog.Info("successfully connected to read replica")
+		}
+	}
 
-			log.Println("successfully connected to database")
+	return db, nil
+}
 
-			return db, nil
-		}
+// openPostgres creates the pool without connecting; database/sql connects on first use.
+func openPostgres(pg config.PostgresConfig) (*sql.DB, error) {
+	db, err := otelsql.Open(pg.Name, pg.DSN(), tracing.SQLOptions(semconv.DBSystemPostgreSQL)...)
+	if err != nil {
+		return nil, err
 	}
+	configurePool(db, pg.Pool)
+	return db, nil
+}
 
-	return nil, errors.New("failed to connect to database, please check your database configuration")
+// configurePool applies the Pool config, keeping the previous hardcoded sizes as defaults.
+func configurePool(db *sql.DB, pool config.PoolConfig) {
+	maxOpen := pool.MaxOpenConns
+	if maxOpen == 0 {
+		maxOpen = 100
+	}
+	maxIdle := pool.MaxIdleConns
+	if maxIdle == 0 {
+		maxIdle = 5
+	}
+	lifetime := time.Duration(pool.ConnMaxLifetimeInSecond) * time.Second
+	if lifetime == 0 {
+		lifetime = 60 * time.Minute
+	}
+	idleTime := time.Duration(pool.ConnMaxIdleTimeInSecond) * time.Second
+	if idleTime == 0 {
+		idleTime = 10 * time.Minute
+	}
 
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	db.SetConnMaxIdleTime(idleTime)
 }
@@ -0,0 +1,107 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRunInTx_ObservesTransaction(t *testing.T) {
+	db, _ := databasetest.New()
+	ctx := metrics.WithTransaction(context.Background(), "test", "RunInTx")
+
+	before, err := testutil.GatherAndCount(metrics.Registry, "go_management_usecase_transaction_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := testutil.GatherAndCount(metrics.Registry, "go_management_usecase_transaction_duration_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after-before != 1 {
+		t.Errorf("new usecase_transaction_duration_seconds series = %d, want 1", after-before)
+	}
+}
+
+func TestRunInTx_Retry(t *testing.T) {
+	errConflict := &pq.Error{Code: "40001"}
+	errOther := errors.New("boom")
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantErr      error
+		wantAttempts int
+		wantCommits  int
+	}{
+		{name: "success", errs: []error{nil}, wantAttempts: 1, wantCommits: 1},
+		{name: "conflict then success", errs: []error{errConflict, nil}, wantAttempts: 2, wantCommits: 1},
+		{name: "deadlock then success", errs: []error{&pq.Error{Code: "40P01"}, nil}, wantAttempts: 2, wantCommits: 1},
+		{name: "not retryable", errs: []error{errOther, nil}, wantErr: errOther, wantAttempts: 1},
+		{name: "attempts exhausted", errs: []error{errConflict, errConflict, errConflict, nil}, wantErr: errConflict, wantAttempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, stats := databasetest.New()
+
+			attempts := 0
+			err := db.RunInTx(context.Background(), nil, func(tx *sql.Tx) error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunInTx() error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if stats.Begins() != tt.wantAttempts || stats.Commits() != tt.wantCommits || stats.Rollbacks() != tt.wantAttempts-tt.wantCommits {
+				t.Errorf("begins = %d, commits = %d, rollbacks = %d, want %d, %d and %d",
+					stats.Begins(), stats.Commits(), stats.Rollbacks(), tt.wantAttempts, tt.wantCommits, tt.wantAttempts-tt.wantCommits)
+			}
+		})
+	}
+}
+
+func TestBeginTx_Routing(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		opts        *sql.TxOptions
+		wantReplica bool
+	}{
+		{name: "write", ctx: context.Background()},
+		{name: "read only", ctx: context.Background(), opts: &sql.TxOptions{ReadOnly: true}, wantReplica: true},
+		{name: "read only on primary", ctx: database.WithPrimary(context.Background()), opts: &sql.TxOptions{ReadOnly: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, primary, replica := databasetest.NewWithReplica()
+
+			tx, err := db.BeginTx(tt.ctx, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx.Rollback()
+
+			if got := replica.Begins() == 1 && primary.Begins() == 0; got != tt.wantReplica {
+				t.Errorf("primary begins = %d, replica begins = %d, want replica %v", primary.Begins(), replica.Begins(), tt.wantReplica)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/lib/pq"
+)
+
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// DB is the Postgres pool used by the usecases. ReadOnly transactions go to the read replica when
+// one is configured; write transactions run through RunInTx so conflicts are retried.
+type DB struct {
+	primary   *sql.DB
+	replica   *sql.DB
+	txBackoff Backoff
+}
+
+// NewDB wraps existing pools; replica may be nil.
+func NewDB(primary, replica *sql.DB, txBackoff Backoff) *DB {
+	return &DB{primary: primary, replica: replica, txBackoff: txBackoff}
+}
+
+func (d *DB) Primary() *sql.DB {
+	return d.primary
+}
+
+// Replica returns the read replica pool, nil when none is configured.
+func (d *DB) Replica() *sql.DB {
+	return d.replica
+}
+
+type primaryKey struct{}
+
+// WithPrimary makes the transactions started with ctx run on the primary even when they are
+// ReadOnly. Lookups that must see the latest writes, such as token revocation, use it because the
+// replica may lag behind.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// BeginTx starts a transaction on the replica for ReadOnly options and on the primary otherwise.
+// When the replica cannot start a transaction, e.g. during its failover, the primary is used.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if d.replica != nil && opts != nil && opts.ReadOnly && !usePrimary(ctx) {
+		tx, err := d.replica.BeginTx(ctx, opts)
+		if err == nil {
+			return tx, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "read replica unavailable, reading from primary", slog.Any("error", err))
+	}
+	return d.primary.BeginTx(ctx, opts)
+}
+
+// RunInTx runs fn in a transaction and commits it. When the transaction fails with a
+// serialization failure or a deadlock it is rolled back and fn runs again from the start, so fn
+// must only change the database through tx; side effects such as sending mail belong after RunInTx.
+//
+// The retry is meant for transactions that set sql.LevelRepeatableRead or sql.LevelSerializable in
+// opts: at those levels Postgres reports a conflicting concurrent write as a serialization failure
+// (40001). At the default ReadCommitted level that error practically never occurs, so only
+// deadlocks (40P01) are retried there and lost updates must be prevented by the queries themselves,
+// e.g. with the version checks of the repositories.
+// The time spent, retries included, is recorded for the usecase method labelled with
+// metrics.WithTransaction.
+func (d *DB) RunInTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	defer metrics.ObserveTransaction(ctx, time.Now())
+
+	for attempt := 1; ; attempt++ {
+		err := d.runInTx(ctx, opts, fn)
+		if err == nil || !IsRetryable(err) || attempt >= d.txBackoff.MaxAttempts {
+			return err
+		}
+
+		delay := d.txBackoff.Delay(attempt)
+		slog.WarnContext(ctx, "transaction conflict, retrying",
+			slog.Int("attempt", attempt), slog.Duration("retry_in", delay), slog.Any("error", err))
+		if sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+func (d *DB) runInTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := d.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// IsRetryable reports whether err aborted the transaction only because of a concurrent one, so
+// running it again can succeed.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
+
+func (d *DB) Close() error {
+	err := d.primary.Close()
+	if d.replica != nil {
+		err = errors.Join(err, d.replica.Close())
+	}
+	return err
+}
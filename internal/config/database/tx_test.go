@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/lib/pq"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("update card: %w", &pq.Error{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}},
+		{name: "other error", err: errors.New("boom")},
+		{name: "nil", err: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPostgresDB_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewPostgresDB(ctx, &config.DatabaseConfig{PostgresSql: config.PostgresConfig{Name: "postgres", Host: "127.0.0.1", Port: "1"}})
+	if err == nil {
+		t.Fatal("NewPostgresDB() error = nil, want an error when the context is canceled")
+	}
+}
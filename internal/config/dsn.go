@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DSN builds a keyword/value connection string for lib/pq. Values are quoted and escaped, so
+// passwords with spaces, quotes or backslashes survive.
+func (c PostgresConfig) DSN() string {
+	params := []struct{ key, value string }{
+		{"host", c.Host},
+		{"port", c.Port},
+		{"user", c.Username},
+		{"password", c.Password},
+		{"dbname", c.DatabaseName},
+		{"sslmode", c.SSLMode},
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+		{"TimeZone", c.TimeZone},
+	}
+	if c.ConnectTimeoutInSecond > 0 {
+		params = append(params, struct{ key, value string }{"connect_timeout", strconv.Itoa(c.ConnectTimeoutInSecond)})
+	}
+	if c.StatementTimeoutInSecond > 0 {
+		// statement_timeout dikirim sebagai runtime parameter dalam milidetik
+		params = append(params, struct{ key, value string }{"statement_timeout", strconv.Itoa(c.StatementTimeoutInSecond * 1000)})
+	}
+	for _, param := range c.Parameters {
+		params = append(params, struct{ key, value string }{param.Name, param.Value})
+	}
+
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		// password kosong tetap dikirim supaya tidak diambil dari PGPASSWORD secara diam-diam
+		if param.value == "" && param.key != "password" {
+			continue
+		}
+		parts = append(parts, param.key+"="+quotePostgresValue(param.value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quotePostgresValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
+
+// DSN builds a go-sql-driver/mysql connection string. Query parameters are URL-encoded; the
+// driver splits user and password at the first colon and the address at the last @, so the
+// password may contain any character.
+func (c MySqlConfig) DSN() string {
+	params := url.Values{}
+	for _, param := range c.Parameters {
+		params.Set(param.Name, param.Value)
+	}
+	if c.TLS != "" {
+		params.Set("tls", c.TLS)
+	}
+	if c.TimeZone != "" {
+		params.Set("loc", c.TimeZone)
+	}
+	if c.TimeoutInSecond > 0 {
+		params.Set("timeout", fmt.Sprintf("%ds", c.TimeoutInSecond))
+	}
+	if c.ReadTimeoutInSecond > 0 {
+		params.Set("readTimeout", fmt.Sprintf("%ds", c.ReadTimeoutInSecond))
+	}
+	if c.WriteTimeoutInSecond > 0 {
+		params.Set("writeTimeout", fmt.Sprintf("%ds", c.WriteTimeoutInSecond))
+	}
+
+	var b strings.Builder
+	b.WriteString(c.Username)
+	if c.Password != "" {
+		b.WriteString(":" + c.Password)
+	}
+	b.WriteString("@tcp(" + net.JoinHostPort(c.Host, c.Port) + ")/" + c.DatabaseName)
+	if len(params) > 0 {
+		// Encode mengurutkan key, jadi hasilnya stabil
+		b.WriteString("?" + params.Encode())
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestPostgresConfig_DSN(t *testing.T) {
+	base := PostgresConfig{Host: "localhost", Port: "5432", Username: "postgres", DatabaseName: "go-management"}
+
+	tests := []struct {
+		name   string
+		modify func(c *PostgresConfig)
+		want   string
+	}{
+		{
+			name:   "plain password",
+			modify: func(c *PostgresConfig) { c.Password = "welcome1" },
+			want:   "host=localhost port=5432 user=postgres password=welcome1 dbname=go-management",
+		},
+		{
+			name:   "password with space, quote and backslash",
+			modify: func(c *PostgresConfig) { c.Password = `we l'c\ome` },
+			want:   `host=localhost port=5432 user=postgres password='we l\'c\\ome' dbname=go-management`,
+		},
+		{
+			name:   "empty password is sent explicitly",
+			modify: func(c *PostgresConfig) {},
+			want:   "host=localhost port=5432 user=postgres password='' dbname=go-management",
+		},
+		{
+			name: "ssl, timezone, timeouts and parameters",
+			modify: func(c *PostgresConfig) {
+				c.Password = "secret"
+				c.SSLMode = "verify-full"
+				c.SSLRootCert = "/etc/ssl/db ca.pem"
+				c.TimeZone = "Asia/Jakarta"
+				c.ConnectTimeoutInSecond = 5
+				c.StatementTimeoutInSecond = 30
+				c.Parameters = []DSNParameter{{Name: "application_name", Value: "go management"}, {Name: "search_path", Value: "app"}}
+			},
+			want: "host=localhost port=5432 user=postgres password=secret dbname=go-management sslmode=verify-full " +
+				"sslrootcert='/etc/ssl/db ca.pem' TimeZone=Asia/Jakarta connect_timeout=5 statement_timeout=30000 " +
+				"application_name='go management' search_path=app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := base
+			tt.modify(&c)
+
+			got := c.DSN()
+			if got != tt.want {
+				t.Errorf("DSN() = %s, want %s", got, tt.want)
+			}
+			if _, err := pq.NewConnector(got); err != nil {
+				t.Errorf("lib/pq rejects DSN() %s: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestMySqlConfig_DSN(t *testing.T) {
+	tests := []struct {
+		name   string
+		config MySqlConfig
+		want   string
+	}{
+		{
+			name:   "without parameters",
+			config: MySqlConfig{Host: "localhost", Port: "3306", Username: "root", Password: "secret", DatabaseName: "app"},
+			want:   "root:secret@tcp(localhost:3306)/app",
+		},
+		{
+			name: "special characters and parameters",
+			config: MySqlConfig{
+				Host: "db", Port: "3306", Username: "root", Password: "p@ss:w/rd", DatabaseName: "app",
+				TLS: "skip-verify", TimeZone: "Asia/Jakarta", TimeoutInSecond: 5,
+				Parameters: []DSNParameter{{Name: "parseTime", Value: "true"}},
+			},
+			want: "root:p@ss:w/rd@tcp(db:3306)/app?loc=Asia%2FJakarta&parseTime=true&timeout=5s&tls=skip-verify",
+		},
+		{
+			name:   "ipv6 host",
+			config: MySqlConfig{Host: "::1", Port: "3306", Username: "root", DatabaseName: "app"},
+			want:   "root@tcp([::1]:3306)/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.DSN(); got != tt.want {
+				t.Errorf("DSN() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
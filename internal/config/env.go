@@ -0,0 +1,63 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	DefaultProfile   = "local"
+	DefaultDirectory = "./properties"
+
+	// EnvPrefix prefixes the environment variables overriding config keys.
+	EnvPrefix = "APP"
+	// EnvProfile and EnvDirectory select the properties file when no flag is given.
+	EnvProfile   = "APP_PROFILE"
+	EnvDirectory = "APP_CONFIG_DIR"
+)
+
+// EnvName returns the environment variable overriding a config key, e.g. Database.PostgresSQL.Host
+// is APP_DATABASE_POSTGRESSQL_HOST.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// bindEnvs binds every key of the config struct, so an environment variable also works for keys
+// that are missing in the properties file. Maps and lists of structs can only be set in the file.
+func bindEnvs(v *viper.Viper) error {
+	for _, key := range configKeys(reflect.TypeOf(config{}), "") {
+		if err := v.BindEnv(key, EnvName(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		switch {
+		case fieldType.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(fieldType, key+".")...)
+		case field.Type.Kind() == reflect.Map:
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
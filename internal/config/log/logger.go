@@ -1 +1,118 @@
 package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/constant"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewLogger builds the application logger from ApplicationConfig.Log. Records logged with a
+// context automatically carry the request ID, user ID and trace of that context. The logger
+// follows level, so the level can be changed at runtime with level.Set.
+func NewLogger(cfg *config.ApplicationConfig, w io.Writer, level *slog.LevelVar) (*slog.Logger, error) {
+	parsed, err := ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(parsed)
+
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Log.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Log.Format)
+	}
+
+	return slog.New(&contextHandler{Handler: handler}).With(slog.String("app", cfg.Name)), nil
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, constant.RequestIDKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(constant.RequestIDKey).(string)
+	return requestID
+}
+
+// requestError is an error annotated with the request that caused it, so an error that is logged or
+// returned away from its request can still be found next to the access log.
+type requestError struct {
+	requestID string
+	err       error
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("request %s: %v", e.requestID, e.err)
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// WrapError adds the request ID of ctx to err. It returns err unchanged when err is nil, ctx has no
+// request ID or err already carries one.
+func WrapError(ctx context.Context, err error) error {
+	requestID := RequestIDFromContext(ctx)
+	if err == nil || requestID == "" {
+		return err
+	}
+	var wrapped *requestError
+	if errors.As(err, &wrapped) {
+		return err
+	}
+	return &requestError{requestID: requestID, err: err}
+}
+
+// contextHandler adds the request correlation attributes of the context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if userID, ok := ctx.Value(constant.UserIDKey).(int64); ok {
+		record.AddAttrs(slog.Int64("user_id", userID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
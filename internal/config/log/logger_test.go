@@ -0,0 +1,111 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/constant"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LogConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: config.LogConfig{}},
+		{name: "text debug", cfg: config.LogConfig{Level: "debug", Format: "text"}},
+		{name: "unknown level", cfg: config.LogConfig{Level: "verbose"}, wantErr: true},
+		{name: "unknown format", cfg: config.LogConfig{Format: "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLogger(&config.ApplicationConfig{Name: "test", Log: tt.cfg}, &bytes.Buffer{}, new(slog.LevelVar))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLogger_ContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&config.ApplicationConfig{Name: "test"}, &buf, new(slog.LevelVar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	ctx = context.WithValue(ctx, constant.UserIDKey, int64(42))
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}))
+	logger.InfoContext(ctx, "hello")
+	logger.DebugContext(ctx, "below the default level")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected exactly one JSON record, got %q", buf.String())
+	}
+	if record["request_id"] != "req-123" || record["user_id"] != float64(42) || record["app"] != "test" {
+		t.Errorf("record = %v, want request_id, user_id and app attributes", record)
+	}
+	if record["trace_id"] != "01000000000000000000000000000000" || record["span_id"] != "0200000000000000" {
+		t.Errorf("record = %v, want trace_id and span_id attributes", record)
+	}
+}
+
+func TestNewLogger_LevelChange(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	logger, err := NewLogger(&config.ApplicationConfig{Name: "test", Log: config.LogConfig{Level: "info"}}, &buf, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debug("dropped")
+	level.Set(slog.LevelDebug)
+	logger.Debug("kept")
+
+	if bytes.Contains(buf.Bytes(), []byte("dropped")) || !bytes.Contains(buf.Bytes(), []byte("kept")) {
+		t.Errorf("output = %q, want only the record logged after the level change", buf.String())
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	cause := errors.New("connection reset")
+	ctx := WithRequestID(context.Background(), "req-123")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		wantMsg string
+	}{
+		{name: "nil error", ctx: ctx, err: nil},
+		{name: "no request ID", ctx: context.Background(), err: cause, wantMsg: "connection reset"},
+		{name: "request ID", ctx: ctx, err: cause, wantMsg: "request req-123: connection reset"},
+		{name: "already wrapped", ctx: ctx, err: WrapError(ctx, cause), wantMsg: "request req-123: connection reset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapError(tt.ctx, tt.err)
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("WrapError() = %v, want nil", err)
+				}
+				return
+			}
+			if err.Error() != tt.wantMsg || !errors.Is(err, cause) {
+				t.Errorf("WrapError() = %v, want %q wrapping the cause", err, tt.wantMsg)
+			}
+		})
+	}
+}
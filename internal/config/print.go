@@ -0,0 +1,67 @@
+package config
+
+import (
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redactedValue = "******"
+
+func (c *config) Print(w io.Writer, redacted bool) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(settings(reflect.ValueOf(*c), redacted)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// settings converts a config value to maps keyed like the properties file, replacing the
+// non-empty fields tagged secret when redacted.
+func settings(v reflect.Value, redacted bool) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return settings(v.Elem(), redacted)
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Tag.Get("mapstructure")
+			if name == "" {
+				name = field.Name
+			}
+			if redacted && field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+				out[name] = redactedValue
+				continue
+			}
+			out[name] = settings(v.Field(i), redacted)
+		}
+		return out
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = settings(v.Index(i), redacted)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = settings(iter.Value(), redacted)
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// RuntimeConfig holds the settings that are applied again when the properties file changes.
+// The mapstructure names are the keys in the properties file, used to report changes.
+type RuntimeConfig struct {
+	LogLevel  string          `mapstructure:"Application.Log.Level"`
+	RateLimit RateLimitConfig `mapstructure:"RateLimit"`
+	// Features maps a lowercase flag name to whether it is on.
+	Features map[string]bool `mapstructure:"Features"`
+	Cors     CorsConfig      `mapstructure:"Cors"`
+}
+
+// FeatureEnabled reports whether the flag is on; flags missing in the properties file are off.
+func (r RuntimeConfig) FeatureEnabled(name string) bool {
+	return r.Features[strings.ToLower(name)]
+}
+
+// Change is one reloaded key with its old and new value.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Key, c.Old, c.New)
+}
+
+// Subscriber applies a reloaded RuntimeConfig; changes lists what differs from the previous one.
+type Subscriber func(runtime RuntimeConfig, changes []Change)
+
+// Runtime keeps the current RuntimeConfig. A reload replaces it as a whole and only after the
+// complete file passed validation, so readers never see a half-applied or invalid config.
+type Runtime struct {
+	current atomic.Pointer[RuntimeConfig]
+
+	mu          sync.Mutex
+	subscribers []Subscriber
+}
+
+func newRuntime(c *config) *Runtime {
+	r := &Runtime{}
+	runtime := c.runtimeConfig()
+	r.current.Store(&runtime)
+	return r
+}
+
+func (r *Runtime) Current() RuntimeConfig {
+	return *r.current.Load()
+}
+
+// Subscribe registers fn for future reloads. Subscribers run one after another in registration order.
+func (r *Runtime) Subscribe(fn Subscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// update stores next and notifies the subscribers; it returns the changes, nil when nothing changed.
+// The subscribers run after the lock is released, so they may call Current or Subscribe.
+func (r *Runtime) update(next RuntimeConfig) []Change {
+	r.mu.Lock()
+	changes := diffSettings(r.Current(), next)
+	if len(changes) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	r.current.Store(&next)
+	subscribers := slices.Clone(r.subscribers)
+	r.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(next, changes)
+	}
+	return changes
+}
+
+func (c *config) runtimeConfig() RuntimeConfig {
+	return RuntimeConfig{
+		LogLevel:  c.Application.Log.Level,
+		RateLimit: c.RateLimit,
+		Features:  c.Features,
+		Cors:      c.Cors,
+	}
+}
+
+func (c *config) Runtime() *Runtime {
+	return c.runtime
+}
+
+// WatchRuntime reloads the runtime settings whenever the properties file is written. An invalid
+// file is logged and ignored; the other settings need a restart to take effect.
+func (c *config) WatchRuntime() {
+	c.viper.OnConfigChange(func(event fsnotify.Event) {
+		next, err := reload(c.viper)
+		if err != nil {
+			slog.Error("config reload rejected, keeping the current settings", slog.String("file", event.Name), slog.Any("error", err))
+			return
+		}
+
+		changes := c.runtime.update(next.runtimeConfig())
+		if len(changes) > 0 {
+			slog.Info("runtime config reloaded", slog.String("file", event.Name), slog.Any("changes", changes))
+		}
+	})
+	c.viper.WatchConfig()
+}
+
+func reload(v *viper.Viper) (*config, error) {
+	var next config
+	if err := v.Unmarshal(&next); err != nil {
+		return nil, err
+	}
+	if err := resolveSecrets(&next); err != nil {
+		return nil, err
+	}
+	if err := validateConfig(&next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// diffSettings compares two values key by key, using the same keys as the properties file.
+func diffSettings(old, next interface{}) []Change {
+	oldValues := map[string]string{}
+	flattenSettings(oldValues, "", settings(reflect.ValueOf(old), true))
+	nextValues := map[string]string{}
+	flattenSettings(nextValues, "", settings(reflect.ValueOf(next), true))
+
+	var changes []Change
+	for key, value := range nextValues {
+		if oldValues[key] != value {
+			changes = append(changes, Change{Key: key, Old: oldValues[key], New: value})
+		}
+	}
+	for key, value := range oldValues {
+		if _, ok := nextValues[key]; !ok {
+			changes = append(changes, Change{Key: key, Old: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func flattenSettings(out map[string]string, prefix string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			flattenSettings(out, prefix+key+".", nested)
+		}
+	case []interface{}:
+		values := make([]string, len(value))
+		for i, nested := range value {
+			values[i] = fmt.Sprint(nested)
+		}
+		out[strings.TrimSuffix(prefix, ".")] = strings.Join(values, ",")
+	default:
+		if value != nil {
+			out[strings.TrimSuffix(prefix, ".")] = fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestDiffSettings(t *testing.T) {
+	old := RuntimeConfig{
+		LogLevel:  "info",
+		RateLimit: RateLimitConfig{Enabled: true, Default: RateLimitRuleConfig{Limit: 60, PeriodInSecond: 60}},
+		Features:  map[string]bool{"registration": true},
+		Cors:      CorsConfig{AllowOrigins: []string{"http://localhost:3000"}},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *RuntimeConfig)
+		want   []Change
+	}{
+		{name: "nothing changed", modify: func(r *RuntimeConfig) {}},
+		{
+			name: "scalar values",
+			modify: func(r *RuntimeConfig) {
+				r.LogLevel = "debug"
+				r.RateLimit.Default.Limit = 120
+			},
+			want: []Change{
+				{Key: "Application.Log.Level", Old: "info", New: "debug"},
+				{Key: "RateLimit.Default.Limit", Old: "60", New: "120"},
+			},
+		},
+		{
+			name: "map entries and lists",
+			modify: func(r *RuntimeConfig) {
+				r.Features = map[string]bool{"export": true}
+				r.Cors.AllowOrigins = []string{"http://localhost:3000", "https://app.example.com"}
+			},
+			want: []Change{
+				{Key: "Cors.AllowOrigins", Old: "http://localhost:3000", New: "http://localhost:3000,https://app.example.com"},
+				{Key: "Features.export", New: "true"},
+				{Key: "Features.registration", Old: "true"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := old
+			next.Cors.AllowOrigins = append([]string(nil), old.Cors.AllowOrigins...)
+			tt.modify(&next)
+
+			if got := diffSettings(old, next); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffSettings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_WatchRuntime(t *testing.T) {
+	dir := writeProperties(t, "test", testProperties+"Features:\n  Export: false\n")
+	loaded, err := LoadConfig(LoadOptions{Profile: "test", Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notified := make(chan []Change, 1)
+	loaded.Runtime().Subscribe(func(runtime RuntimeConfig, changes []Change) {
+		notified <- changes
+	})
+	loaded.WatchRuntime()
+
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "app-test.yml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// port yang tidak valid membuat seluruh reload ditolak
+	write(strings.Replace(testProperties, "Port: 9999", "Port: http", 1) + "Features:\n  Export: true\n")
+	select {
+	case changes := <-notified:
+		t.Fatalf("invalid file was applied: %v", changes)
+	case <-time.After(300 * time.Millisecond):
+	}
+	if loaded.Runtime().Current().FeatureEnabled("export") {
+		t.Fatal("invalid file changed the runtime config")
+	}
+
+	write(testProperties + "Features:\n  Export: true\n")
+	select {
+	case changes := <-notified:
+		want := []Change{{Key: "Features.export", Old: "false", New: "true"}}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("changes = %v, want %v", changes, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber was not notified")
+	}
+	if !loaded.Runtime().Current().FeatureEnabled("Export") {
+		t.Error("FeatureEnabled(Export) = false after reload")
+	}
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties string
+		wantErr    string
+	}{
+		{name: "valid file", properties: testProperties + "Features:\n  Export: true\n"},
+		{name: "invalid value", properties: strings.Replace(testProperties, "Port: 9999", "Port: http", 1), wantErr: "Application.Port must be a number"},
+		{name: "unresolved secret", properties: strings.Replace(testProperties, "TokenSecret: account-secret", "TokenSecret: ${file:/nonexistent/token_secret}", 1), wantErr: "Account.TokenSecret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(strings.NewReader(tt.properties)); err != nil {
+				t.Fatal(err)
+			}
+
+			next, err := reload(v)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("reload() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !next.runtimeConfig().FeatureEnabled("export") {
+				t.Errorf("reload() features = %v, want export on", next.Features)
+			}
+		})
+	}
+}
+
+func TestRuntime_UpdateNotifiesOutsideLock(t *testing.T) {
+	r := &Runtime{}
+	r.current.Store(&RuntimeConfig{LogLevel: "info"})
+
+	var seen RuntimeConfig
+	r.Subscribe(func(runtime RuntimeConfig, changes []Change) {
+		// subscriber boleh memanggil Runtime lagi tanpa deadlock
+		seen = r.Current()
+		r.Subscribe(func(RuntimeConfig, []Change) {})
+	})
+
+	done := make(chan []Change, 1)
+	go func() {
+		done <- r.update(RuntimeConfig{LogLevel: "debug"})
+	}()
+	select {
+	case changes := <-done:
+		if len(changes) != 1 || seen.LogLevel != "debug" {
+			t.Errorf("update() = %v, subscriber saw %+v, want the new log level", changes, seen)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("update() deadlocked while a subscriber called the runtime")
+	}
+
+	if changes := r.update(RuntimeConfig{LogLevel: "debug"}); changes != nil {
+		t.Errorf("update() without changes = %v, want nil", changes)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvSecretsKey holds the base64 AES-256 key of the encrypted secrets file. It is only read from
+// the environment so the key never sits next to the file it protects.
+const EnvSecretsKey = "APP_SECRETS_KEY"
+
+// secretsAssociatedData binds the ciphertext to this format, so other AES-GCM blobs are rejected.
+var secretsAssociatedData = []byte("go-management-project/secrets/v1")
+
+// GenerateSecretsKey returns a new random key, base64 encoded for EnvSecretsKey.
+func GenerateSecretsKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func ParseSecretsKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("secrets key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// EncryptSecrets seals plaintext (a YAML map of secret name to value) with AES-256-GCM. The result
+// is base64 of nonce followed by ciphertext, so it can be committed or mounted as a text file.
+func EncryptSecrets(key, plaintext []byte) ([]byte, error) {
+	aead, err := newSecretsAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, secretsAssociatedData)
+
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+	return []byte(encoded + "\n"), nil
+}
+
+func DecryptSecrets(key, data []byte) ([]byte, error) {
+	aead, err := newSecretsAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("secrets file is not valid base64: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("secrets file is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, secretsAssociatedData)
+	if err != nil {
+		// pesan sengaja umum, key salah dan file rusak tidak bisa dibedakan
+		return nil, errors.New("cannot decrypt secrets file: wrong key or corrupted file")
+	}
+	return plaintext, nil
+}
+
+func newSecretsAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretResolver replaces ${file:<path>} and ${secret:<name>} values. The secrets file is only
+// decrypted when a value refers to it.
+type secretResolver struct {
+	file    string
+	secrets map[string]string
+}
+
+func (r *secretResolver) resolve(value string) (string, error) {
+	if !strings.HasPrefix(value, "${") || !strings.HasSuffix(value, "}") {
+		return value, nil
+	}
+	kind, ref, ok := strings.Cut(value[2:len(value)-1], ":")
+	if !ok {
+		return value, nil
+	}
+
+	switch kind {
+	case "file":
+		content, err := os.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+		// secret mount biasanya diakhiri newline
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case "secret":
+		if err := r.load(); err != nil {
+			return "", err
+		}
+		secret, ok := r.secrets[ref]
+		if !ok {
+			return "", fmt.Errorf("secret %q not found in %s", ref, r.file)
+		}
+		return secret, nil
+	default:
+		return value, nil
+	}
+}
+
+func (r *secretResolver) load() error {
+	if r.secrets != nil {
+		return nil
+	}
+	if r.file == "" {
+		return errors.New("secrets file is not set, see Secrets.File")
+	}
+
+	key, err := ParseSecretsKey(os.Getenv(EnvSecretsKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w", EnvSecretsKey, err)
+	}
+	data, err := os.ReadFile(r.file)
+	if err != nil {
+		return err
+	}
+	plaintext, err := DecryptSecrets(key, data)
+	if err != nil {
+		return err
+	}
+
+	secrets := map[string]string{}
+	if err := yaml.Unmarshal(plaintext, &secrets); err != nil {
+		return fmt.Errorf("secrets file must be a map of name to value: %w", err)
+	}
+	r.secrets = secrets
+	return nil
+}
+
+// resolveSecrets replaces the secret references in every string of c, reporting each failure
+// with its key.
+func resolveSecrets(c *config) error {
+	r := &secretResolver{file: c.Secrets.File}
+	var errs []error
+	resolveValue(r, reflect.ValueOf(c).Elem(), "", &errs)
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func resolveValue(r *secretResolver, v reflect.Value, key string, errs *[]error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			resolveValue(r, v.Elem(), key, errs)
+		}
+	case reflect.String:
+		resolved, err := r.resolve(v.String())
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+			return
+		}
+		v.SetString(resolved)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Tag.Get("mapstructure")
+			if name == "" {
+				name = field.Name
+			}
+			resolveValue(r, v.Field(i), strings.TrimPrefix(key+"."+name, "."), errs)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			resolveValue(r, v.Index(i), fmt.Sprintf("%s[%d]", key, i), errs)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			resolved, err := r.resolve(iter.Value().String())
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s.%s: %w", key, iter.Key().String(), err))
+				continue
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(resolved))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptSecrets(t *testing.T) {
+	key, err := GenerateSecretsKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _ := GenerateSecretsKey()
+
+	plaintext := []byte("db_password: welcome1\n")
+	decoded, _ := ParseSecretsKey(key)
+	sealed, err := EncryptSecrets(decoded, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[20] = 'A'
+	if sealed[20] == 'A' {
+		tampered[20] = 'B'
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "same key", key: key, data: sealed},
+		{name: "other key", key: otherKey, data: sealed, wantErr: true},
+		{name: "tampered file", key: key, data: tampered, wantErr: true},
+		{name: "not base64", key: key, data: []byte("db_password: welcome1"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, _ := ParseSecretsKey(tt.key)
+			got, err := DecryptSecrets(k, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecryptSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != string(plaintext) {
+				t.Errorf("DecryptSecrets() = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_SecretReferences(t *testing.T) {
+	secretDir := t.TempDir()
+	passwordFile := filepath.Join(secretDir, "db_password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, _ := GenerateSecretsKey()
+	decoded, _ := ParseSecretsKey(key)
+	sealed, err := EncryptSecrets(decoded, []byte("account_token_secret: from-store\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretsFile := filepath.Join(secretDir, "secrets.enc")
+	if err := os.WriteFile(secretsFile, sealed, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := strings.NewReplacer(
+		"Password: welcome1", "Password: ${file:"+passwordFile+"}",
+		"TokenSecret: account-secret", "TokenSecret: ${secret:account_token_secret}",
+	).Replace(testProperties) + "Secrets:\n  File: " + secretsFile + "\n"
+	dir := writeProperties(t, "test", properties)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "resolved", env: map[string]string{EnvSecretsKey: key}},
+		{name: "missing key", wantErr: EnvSecretsKey},
+		{name: "unknown secret", env: map[string]string{EnvSecretsKey: key, "APP_JWT_SECRETKEY": "${secret:jwt}"}, wantErr: `Jwt.SecretKey: secret "jwt" not found`},
+		{name: "missing file", env: map[string]string{EnvSecretsKey: key, "APP_OIDC_CLIENTSECRET": "${file:/does/not/exist}"}, wantErr: "Oidc.ClientSecret: read secret file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			loaded, err := LoadConfig(LoadOptions{Profile: "test", Directory: dir})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := loaded.(*config)
+			if c.Database.PostgresSql.Password != "from-file" || c.Account.TokenSecret != "from-store" {
+				t.Errorf("Password = %q, TokenSecret = %q, want the referenced secrets", c.Database.PostgresSql.Password, c.Account.TokenSecret)
+			}
+		})
+	}
+}
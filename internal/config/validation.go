@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// pakai nama key di file properties supaya pesan error bisa langsung dicari di yml
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// validateConfig reports every invalid key at once, each with the environment variable that
+// overrides it, so a broken deployment is fixed in one go.
+func validateConfig(c *config) error {
+	err := configValidator.Struct(c)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		// namespace diawali nama struct, misal config.Database.PostgresSQL.Host
+		_, key, _ := strings.Cut(fieldErr.Namespace(), ".")
+		messages = append(messages, fmt.Sprintf("%s %s (%s)", key, fieldMessage(fieldErr), EnvName(key)))
+	}
+	return fmt.Errorf("invalid config:\n  %s", strings.Join(messages, "\n  "))
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		field, value, _ := strings.Cut(fieldErr.Param(), " ")
+		return fmt.Sprintf("is required when %s is %s", field, value)
+	case "numeric":
+		return "must be a number"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	case "url":
+		return "must be a URL"
+	case "startswith":
+		return fmt.Sprintf("must start with %q", fieldErr.Param())
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "gte":
+		return "must be at least " + fieldErr.Param()
+	case "gtfield":
+		return "must be greater than " + fieldErr.Param()
+	case "lte":
+		return "must be at most " + fieldErr.Param()
+	default:
+		return "is invalid"
+	}
+}
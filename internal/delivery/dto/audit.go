@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+// AuditResponse exposes the audit columns read-only; clients never send them.
+type AuditResponse struct {
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy int64     `json:"created_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy int64     `json:"updated_by"`
+	Version   int64     `json:"version"`
+}
+
+func newAuditResponse(audit model.Audit) AuditResponse {
+	return AuditResponse{
+		CreatedAt: audit.CreatedAt,
+		CreatedBy: audit.CreatedBy,
+		UpdatedAt: audit.UpdatedAt,
+		UpdatedBy: audit.UpdatedBy,
+		Version:   audit.Version,
+	}
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/MCPutro/go-management-project/internal/model"
+
+type RegisterRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+	// bcrypt hanya memakai 72 byte pertama
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (r *RegisterRequest) ToModel() *model.User {
+	return &model.User{Name: r.Name, Email: r.Email, Password: r.Password}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	// Code berisi kode TOTP atau salah satu recovery code
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RegisterResponse struct {
+	User  *UserResponse    `json:"user"`
+	Token *model.TokenPair `json:"token"`
+}
@@ -0,0 +1,74 @@
+package dto
+
+import "github.com/MCPutro/go-management-project/internal/model"
+
+type CreateCardRequest struct {
+	ListID   int64  `json:"list_id" validate:"required,gt=0"`
+	Title    string `json:"title" validate:"required,max=200"`
+	Content  string `json:"content" validate:"max=10000"`
+	Position int    `json:"position" validate:"gte=0"`
+}
+
+func (r *CreateCardRequest) ToModel(userID int64) *model.Card {
+	return &model.Card{
+		ListID:   r.ListID,
+		Title:    r.Title,
+		Content:  r.Content,
+		Position: r.Position,
+		Audit:    model.Audit{CreatedBy: userID, UpdatedBy: userID},
+	}
+}
+
+type UpdateCardRequest struct {
+	Title    string `json:"title" validate:"required,max=200"`
+	Content  string `json:"content" validate:"max=10000"`
+	Position int    `json:"position" validate:"gte=0"`
+}
+
+func (r *UpdateCardRequest) ToModel(id, userID int64) *model.Card {
+	return &model.Card{
+		ID:       id,
+		Title:    r.Title,
+		Content:  r.Content,
+		Position: r.Position,
+		Audit:    model.Audit{UpdatedBy: userID},
+	}
+}
+
+type PatchCardRequest struct {
+	Title    *string `json:"title" validate:"omitnil,min=1,max=200"`
+	Content  *string `json:"content" validate:"omitnil,max=10000" merge:"nullable"`
+	Position *int    `json:"position" validate:"omitnil,gte=0"`
+}
+
+func (r *PatchCardRequest) ToModel(userID int64) *model.CardPatch {
+	return &model.CardPatch{Title: r.Title, Content: r.Content, Position: r.Position, UpdatedBy: userID}
+}
+
+type CardResponse struct {
+	ID       int64  `json:"id"`
+	ListID   int64  `json:"list_id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Position int    `json:"position"`
+	AuditResponse
+}
+
+func NewCardResponse(card *model.Card) *CardResponse {
+	return &CardResponse{
+		ID:            card.ID,
+		ListID:        card.ListID,
+		Title:         card.Title,
+		Content:       card.Content,
+		Position:      card.Position,
+		AuditResponse: newAuditResponse(card.Audit),
+	}
+}
+
+func NewCardResponses(cards []*model.Card) []*CardResponse {
+	responses := make([]*CardResponse, 0, len(cards))
+	for _, card := range cards {
+		responses = append(responses, NewCardResponse(card))
+	}
+	return responses
+}
@@ -0,0 +1,67 @@
+package dto
+
+import "github.com/MCPutro/go-management-project/internal/model"
+
+type CreateListRequest struct {
+	ProjectID int64  `json:"project_id" validate:"required,gt=0"`
+	Name      string `json:"name" validate:"required,max=150"`
+	Position  int    `json:"position" validate:"gte=0"`
+}
+
+func (r *CreateListRequest) ToModel(userID int64) *model.List {
+	return &model.List{
+		ProjectID: r.ProjectID,
+		Name:      r.Name,
+		Position:  r.Position,
+		Audit:     model.Audit{CreatedBy: userID, UpdatedBy: userID},
+	}
+}
+
+type UpdateListRequest struct {
+	Name     string `json:"name" validate:"required,max=150"`
+	Position int    `json:"position" validate:"gte=0"`
+}
+
+func (r *UpdateListRequest) ToModel(id, userID int64) *model.List {
+	return &model.List{
+		ID:       id,
+		Name:     r.Name,
+		Position: r.Position,
+		Audit:    model.Audit{UpdatedBy: userID},
+	}
+}
+
+type PatchListRequest struct {
+	Name     *string `json:"name" validate:"omitnil,min=1,max=150"`
+	Position *int    `json:"position" validate:"omitnil,gte=0"`
+}
+
+func (r *PatchListRequest) ToModel(userID int64) *model.ListPatch {
+	return &model.ListPatch{Name: r.Name, Position: r.Position, UpdatedBy: userID}
+}
+
+type ListResponse struct {
+	ID        int64  `json:"id"`
+	ProjectID int64  `json:"project_id"`
+	Name      string `json:"name"`
+	Position  int    `json:"position"`
+	AuditResponse
+}
+
+func NewListResponse(list *model.List) *ListResponse {
+	return &ListResponse{
+		ID:            list.ID,
+		ProjectID:     list.ProjectID,
+		Name:          list.Name,
+		Position:      list.Position,
+		AuditResponse: newAuditResponse(list.Audit),
+	}
+}
+
+func NewListResponses(lists []*model.List) []*ListResponse {
+	responses := make([]*ListResponse, 0, len(lists))
+	for _, list := range lists {
+		responses = append(responses, NewListResponse(list))
+	}
+	return responses
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+)
+
+// MergePatchContentType is the media type of an RFC 7396 JSON merge patch.
+const MergePatchContentType = "application/merge-patch+json"
+
+// DecodeMergePatch reads an RFC 7396 merge patch into a patch DTO whose fields are pointers.
+//
+// Members that are absent stay nil and are left untouched. A member set to null removes the value:
+// fields tagged `merge:"nullable"` are reset to their zero value, any other field cannot be removed
+// and is reported as a field error. The decoded patch is validated like any other request DTO.
+func DecodeMergePatch(body []byte, patch interface{}) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		// patch yang bukan object akan mengganti seluruh resource, tidak didukung
+		return apperror.Validation("Merge patch must be a JSON object")
+	}
+	if err := json.Unmarshal(body, patch); err != nil {
+		return apperror.Validation("Invalid input, cannot parse JSON")
+	}
+
+	value := reflect.ValueOf(patch).Elem()
+	var fields []apperror.FieldError
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		raw, ok := members[name]
+		if !ok || !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+		if field.Tag.Get("merge") != "nullable" {
+			fields = append(fields, apperror.FieldError{Field: name, Message: "cannot be removed"})
+			continue
+		}
+		// kesalahan deklarasi DTO, bukan kesalahan client
+		if field.Type.Kind() != reflect.Pointer {
+			return fmt.Errorf("merge patch field %s.%s is nullable but not a pointer", value.Type().Name(), field.Name)
+		}
+		value.Field(i).Set(reflect.New(field.Type.Elem()))
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("Request validation failed", fields...)
+	}
+
+	return Validate(patch)
+}
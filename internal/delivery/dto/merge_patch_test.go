@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+)
+
+func TestDecodeMergePatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		want       PatchCardRequest
+		wantFields []apperror.FieldError
+		wantErr    bool
+	}{
+		{
+			name: "absent members stay nil",
+			body: `{"title": "Final"}`,
+			want: PatchCardRequest{Title: stringPtr("Final")},
+		},
+		{
+			name: "null clears a nullable member",
+			body: `{"content": null, "position": 3}`,
+			want: PatchCardRequest{Content: stringPtr(""), Position: intPtr(3)},
+		},
+		{
+			name:       "null cannot remove a required member",
+			body:       `{"title": null}`,
+			wantFields: []apperror.FieldError{{Field: "title", Message: "cannot be removed"}},
+		},
+		{
+			name:       "values are validated",
+			body:       `{"position": -1}`,
+			wantFields: []apperror.FieldError{{Field: "position", Message: "must be greater than or equal to 0"}},
+		},
+		{
+			name:    "patch must be an object",
+			body:    `["title"]`,
+			wantErr: true,
+		},
+		{
+			name:    "null document",
+			body:    `null`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PatchCardRequest
+			err := DecodeMergePatch([]byte(tt.body), &got)
+
+			if tt.wantErr || tt.wantFields != nil {
+				var appErr *apperror.Error
+				if !errors.As(err, &appErr) || appErr.Kind != apperror.KindValidation {
+					t.Fatalf("DecodeMergePatch() error = %v, want validation error", err)
+				}
+				if tt.wantFields != nil && !reflect.DeepEqual(appErr.Fields, tt.wantFields) {
+					t.Errorf("DecodeMergePatch() fields = %+v, want %+v", appErr.Fields, tt.wantFields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeMergePatch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeMergePatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMergePatch_NullableNonPointer(t *testing.T) {
+	var patch struct {
+		Content string `json:"content" merge:"nullable"`
+	}
+
+	err := DecodeMergePatch([]byte(`{"content": null}`), &patch)
+	if err == nil || apperror.From(err).Kind != apperror.KindInternal {
+		t.Errorf("DecodeMergePatch() error = %v, want an internal error", err)
+	}
+}
@@ -0,0 +1,11 @@
+package dto
+
+type ConfirmMFARequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" validate:"required"`
+	// Code berisi kode TOTP atau salah satu recovery code
+	Code string `json:"code" validate:"required,max=32"`
+}
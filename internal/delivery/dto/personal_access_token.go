@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type CreatePersonalAccessTokenRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=read write"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,future"`
+}
+
+func (r *CreatePersonalAccessTokenRequest) ToModel(userID int64) *model.PersonalAccessToken {
+	return &model.PersonalAccessToken{UserID: userID, Name: r.Name, Scopes: r.Scopes, ExpiresAt: r.ExpiresAt}
+}
+
+type PersonalAccessTokenResponse struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func NewPersonalAccessTokenResponse(token *model.PersonalAccessToken) *PersonalAccessTokenResponse {
+	return &PersonalAccessTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		RevokedAt:   token.RevokedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+func NewPersonalAccessTokenResponses(tokens []*model.PersonalAccessToken) []*PersonalAccessTokenResponse {
+	responses := make([]*PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, NewPersonalAccessTokenResponse(token))
+	}
+	return responses
+}
+
+// CreatedPersonalAccessTokenResponse is the only response that carries the plaintext token.
+type CreatedPersonalAccessTokenResponse struct {
+	Token   string                       `json:"token"`
+	Details *PersonalAccessTokenResponse `json:"details"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import "github.com/MCPutro/go-management-project/internal/model"
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" validate:"required,max=150"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+func (r *CreateProjectRequest) ToModel(userID int64) *model.Project {
+	return &model.Project{
+		Name:        r.Name,
+		Description: r.Description,
+		Audit:       model.Audit{CreatedBy: userID, UpdatedBy: userID},
+	}
+}
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name" validate:"required,max=150"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+func (r *UpdateProjectRequest) ToModel(id, userID int64) *model.Project {
+	return &model.Project{
+		ID:          id,
+		Name:        r.Name,
+		Description: r.Description,
+		Audit:       model.Audit{UpdatedBy: userID},
+	}
+}
+
+type PatchProjectRequest struct {
+	Name        *string `json:"name" validate:"omitnil,min=1,max=150"`
+	Description *string `json:"description" validate:"omitnil,max=2000" merge:"nullable"`
+}
+
+func (r *PatchProjectRequest) ToModel(userID int64) *model.ProjectPatch {
+	return &model.ProjectPatch{Name: r.Name, Description: r.Description, UpdatedBy: userID}
+}
+
+type ProjectResponse struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AuditResponse
+}
+
+func NewProjectResponse(project *model.Project) *ProjectResponse {
+	return &ProjectResponse{
+		ID:            project.ID,
+		Name:          project.Name,
+		Description:   project.Description,
+		AuditResponse: newAuditResponse(project.Audit),
+	}
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+// CreateUserRequest tidak menerima password; user yang dibuat admin mengatur password lewat reset password.
+type CreateUserRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+func (r *CreateUserRequest) ToModel(userID int64) *model.User {
+	return &model.User{
+		Name:  r.Name,
+		Email: r.Email,
+		Audit: model.Audit{CreatedBy: userID, UpdatedBy: userID},
+	}
+}
+
+type UpdateUserRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+func (r *UpdateUserRequest) ToModel(id, userID int64) *model.User {
+	return &model.User{
+		ID:    id,
+		Name:  r.Name,
+		Email: r.Email,
+		Audit: model.Audit{UpdatedBy: userID},
+	}
+}
+
+type PatchUserRequest struct {
+	Name  *string `json:"name" validate:"omitnil,min=1,max=100"`
+	Email *string `json:"email" validate:"omitnil,email,max=255"`
+}
+
+func (r *PatchUserRequest) ToModel(userID int64) *model.UserPatch {
+	return &model.UserPatch{Name: r.Name, Email: r.Email, UpdatedBy: userID}
+}
+
+type UserResponse struct {
+	ID              int64      `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	AuditResponse
+}
+
+func NewUserResponse(user *model.User) *UserResponse {
+	return &UserResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		AuditResponse:   newAuditResponse(user.Audit),
+	}
+}
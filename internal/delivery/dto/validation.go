@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// pakai nama field JSON di pesan error, bukan nama field Go
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		value, ok := fl.Field().Interface().(time.Time)
+		return ok && value.After(time.Now())
+	})
+
+	return v
+}
+
+// Validate runs the validate tags of a request DTO and reports every failing field as an
+// apperror validation error.
+func Validate(request interface{}) error {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, apperror.FieldError{
+			Field:   fieldPath(fieldError),
+			Message: fieldMessage(fieldError),
+		})
+	}
+	return apperror.Validation("Request validation failed", fields...)
+}
+
+// fieldPath drops the struct name from the namespace, e.g. CreateTokenRequest.scopes[0] -> scopes[0].
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return path
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	isString := fieldError.Kind() == reflect.String
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if isString && fieldError.Param() == "1" {
+			return "must not be empty"
+		}
+		if isString {
+			return fmt.Sprintf("must be at least %s characters", fieldError.Param())
+		}
+		if fieldError.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s item(s)", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters", fieldError.Param())
+		}
+		if fieldError.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s item(s)", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldError.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters", fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fieldError.Param(), " ", ", "))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldError.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fieldError.Param())
+	case "numeric":
+		return "must contain only digits"
+	case "future":
+		return "must be in the future"
+	default:
+		return "is not valid"
+	}
+}
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+)
+
+func TestValidate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		request    interface{}
+		wantFields []apperror.FieldError
+	}{
+		{
+			name:    "valid register request",
+			request: &RegisterRequest{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"},
+		},
+		{
+			name:    "missing and malformed fields",
+			request: &RegisterRequest{Email: "budi", Password: "short"},
+			wantFields: []apperror.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "email", Message: "must be a valid email address"},
+				{Field: "password", Message: "must be at least 8 characters"},
+			},
+		},
+		{
+			name:    "unknown scope",
+			request: &CreatePersonalAccessTokenRequest{Name: "ci", Scopes: []string{"read", "admin"}, ExpiresAt: &future},
+			wantFields: []apperror.FieldError{
+				{Field: "scopes[1]", Message: "must be one of: read, write"},
+			},
+		},
+		{
+			name:    "expired token and no scopes",
+			request: &CreatePersonalAccessTokenRequest{Name: "ci", ExpiresAt: &past},
+			wantFields: []apperror.FieldError{
+				{Field: "scopes", Message: "is required"},
+				{Field: "expires_at", Message: "must be in the future"},
+			},
+		},
+		{
+			name:    "list without project",
+			request: &CreateListRequest{Name: "Todo", Position: -1},
+			wantFields: []apperror.FieldError{
+				{Field: "project_id", Message: "is required"},
+				{Field: "position", Message: "must be greater than or equal to 0"},
+			},
+		},
+		{
+			name:    "patch with absent fields",
+			request: &PatchCardRequest{Position: intPtr(2)},
+		},
+		{
+			name:    "patch cannot blank a required field",
+			request: &PatchProjectRequest{Name: stringPtr("")},
+			wantFields: []apperror.FieldError{
+				{Field: "name", Message: "must not be empty"},
+			},
+		},
+		{
+			name:    "confirm code must be six digits",
+			request: &ConfirmMFARequest{Code: "12a456"},
+			wantFields: []apperror.FieldError{
+				{Field: "code", Message: "must contain only digits"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.request)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Kind != apperror.KindValidation {
+				t.Fatalf("Validate() error = %v, want validation error", err)
+			}
+			if !reflect.DeepEqual(appErr.Fields, tt.wantFields) {
+				t.Errorf("Validate() fields = %+v, want %+v", appErr.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"time"
+)
+
+type AccountHandler interface {
+	RequestEmailVerification(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+}
+
+type accountHandler struct {
+	accountUsecase usecase.AccountUsecase
+}
+
+func NewAccountHandler(accountUsecase usecase.AccountUsecase) AccountHandler {
+	return &accountHandler{accountUsecase: accountUsecase}
+}
+
+func (h *accountHandler) RequestEmailVerification(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 15*time.Second)
+	defer cancel()
+
+	err := h.accountUsecase.RequestEmailVerification(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+func (h *accountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	err := h.accountUsecase.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *accountHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 15*time.Second)
+	defer cancel()
+
+	// respons selalu sama, baik email terdaftar maupun tidak
+	if err := h.accountUsecase.RequestPasswordReset(ctx, req.Email); err != nil {
+		slog.ErrorContext(ctx, "failed to request password reset", slog.Any("error", err))
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+func (h *accountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	err := h.accountUsecase.ResetPassword(ctx, req.Token, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"math"
+	"strconv"
+	"time"
+)
+
+type AuthHandler interface {
+	Register(c *fiber.Ctx) error
+	Login(c *fiber.Ctx) error
+	LoginMFA(c *fiber.Ctx) error
+	Refresh(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+}
+
+// featureDisableRegistration closes self-service registration without a restart, e.g. while
+// the service is flooded with fake accounts.
+const featureDisableRegistration = "disable_registration"
+
+type authHandler struct {
+	authUsecase usecase.AuthUsecase
+	runtime     *config.Runtime
+}
+
+func NewAuthHandler(authUsecase usecase.AuthUsecase, runtime *config.Runtime) AuthHandler {
+	return &authHandler{authUsecase: authUsecase, runtime: runtime}
+}
+
+func (h *authHandler) Register(c *fiber.Ctx) error {
+	if h.runtime.Current().FeatureEnabled(featureDisableRegistration) {
+		return apperror.Forbidden("Registration is closed").WithCode("registration_closed")
+	}
+
+	var req dto.RegisterRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	user := req.ToModel()
+	tokenPair, err := h.authUsecase.Register(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.RegisterResponse{
+		User:  dto.NewUserResponse(user),
+		Token: tokenPair,
+	})
+}
+
+func (h *authHandler) Login(c *fiber.Ctx) error {
+	var req dto.LoginRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	tokenPair, challenge, err := h.authUsecase.Login(ctx, req.Email, req.Password, c.IP())
+	if err != nil {
+		if errors.Is(err, utils.ErrTooManyAttempts) {
+			return tooManyAttempts(c, err)
+		}
+		if errors.Is(err, utils.ErrInvalidCredentials) {
+			return apperror.Unauthorized("Invalid email or password").WithCode("invalid_credentials")
+		}
+		return err
+	}
+	if challenge != nil {
+		return c.JSON(challenge)
+	}
+
+	return c.JSON(tokenPair)
+}
+
+func (h *authHandler) LoginMFA(c *fiber.Ctx) error {
+	var req dto.LoginMFARequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	tokenPair, err := h.authUsecase.CompleteMFALogin(ctx, req.MFAToken, req.Code, c.IP())
+	if err != nil {
+		if errors.Is(err, utils.ErrTooManyAttempts) {
+			return tooManyAttempts(c, err)
+		}
+		return err
+	}
+
+	return c.JSON(tokenPair)
+}
+
+func (h *authHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	tokenPair, err := h.authUsecase.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(tokenPair)
+}
+
+// Logout must run behind middleware.JWTAuth, which puts the principal (with the token's jti and expiry) in the context.
+func (h *authHandler) Logout(c *fiber.Ctx) error {
+	// body boleh kosong, refresh token bersifat opsional
+	var req dto.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &req); err != nil {
+			return err
+		}
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	if err := h.authUsecase.Logout(ctx, req.RefreshToken, principal); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tooManyAttempts adds the Retry-After header of a login lockout to the 429 response.
+func tooManyAttempts(c *fiber.Ctx, err error) error {
+	var tooMany *service.TooManyAttemptsError
+	if errors.As(err, &tooMany) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+	}
+	return apperror.TooManyRequests("Too many failed login attempts, please try again later").Wrap(err)
+}
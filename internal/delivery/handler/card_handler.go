@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+type CardHandler interface {
+	CreateCard(c *fiber.Ctx) error
+	GetCard(c *fiber.Ctx) error
+	GetCardsByList(c *fiber.Ctx) error
+	UpdateCard(c *fiber.Ctx) error
+	PatchCard(c *fiber.Ctx) error
+	DeleteCard(c *fiber.Ctx) error
+}
+
+type cardHandler struct {
+	cardUsecase usecase.CardUsecase
+}
+
+func NewCardHandler(cardUsecase usecase.CardUsecase) CardHandler {
+	return &cardHandler{cardUsecase: cardUsecase}
+}
+
+func (h *cardHandler) CreateCard(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	var req dto.CreateCardRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	card := req.ToModel(principal.UserID)
+	if err := h.cardUsecase.CreateCard(ctx, card); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("List not found")
+		}
+		return err
+	}
+
+	setETag(c, card.Version)
+	return c.Status(fiber.StatusCreated).JSON(dto.NewCardResponse(card))
+}
+
+func (h *cardHandler) GetCard(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid card ID format")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	card, err := h.cardUsecase.GetCardByID(ctx, id, principal.UserID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Card not found")
+		}
+		return err
+	}
+
+	setETag(c, card.Version)
+	return c.JSON(dto.NewCardResponse(card))
+}
+
+func (h *cardHandler) GetCardsByList(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	listID, err := strconv.ParseInt(c.Params("list_id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid list ID format")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	cards, err := h.cardUsecase.GetCardsByListID(ctx, listID, principal.UserID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("List not found")
+		}
+		return err
+	}
+
+	// list tanpa card tetap dikembalikan sebagai array kosong
+	return c.JSON(dto.NewCardResponses(cards))
+}
+
+func (h *cardHandler) UpdateCard(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid card ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateCardRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	card := req.ToModel(id, principal.UserID)
+	err = versions.write(func(version int64) error {
+		card.Version = version
+		return h.cardUsecase.UpdateCard(ctx, card)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Card not found")
+		}
+		return err
+	}
+
+	setETag(c, card.Version)
+	return c.JSON(dto.NewCardResponse(card))
+}
+
+func (h *cardHandler) PatchCard(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid card ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PatchCardRequest
+	if err := parseMergePatch(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	patch := req.ToModel(principal.UserID)
+	var card *model.Card
+	err = versions.write(func(version int64) (err error) {
+		patch.Version = version
+		card, err = h.cardUsecase.PatchCard(ctx, id, patch)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Card not found")
+		}
+		return err
+	}
+
+	setETag(c, card.Version)
+	return c.JSON(dto.NewCardResponse(card))
+}
+
+func (h *cardHandler) DeleteCard(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid card ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	err = versions.write(func(version int64) error {
+		return h.cardUsecase.DeleteCard(ctx, id, principal.UserID, version)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Card not found")
+		}
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// setETag sets a strong ETag derived from the row version.
+func setETag(c *fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatch holds the versions listed in If-Match; nil allows any version.
+type ifMatch []int64
+
+// ifMatchVersions parses the If-Match header: no header or "*" allows any version, otherwise the
+// comma separated ETags list the versions the request may change. Only strong ETags as sent by
+// setETag can match; when none of the listed ETags can, the precondition fails right away.
+func ifMatchVersions(c *fiber.Ctx) (ifMatch, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return nil, nil
+	}
+
+	var versions ifMatch
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimSpace(etag)
+		if etag == "*" {
+			return nil, nil
+		}
+
+		// If-Match memakai strong comparison, weak ETag (W/"...") tidak pernah cocok
+		unquoted, err := strconv.Unquote(etag)
+		if err != nil || !strings.HasPrefix(etag, `"`) {
+			continue
+		}
+		version, err := strconv.ParseInt(unquoted, 10, 64)
+		if err != nil || version <= 0 || slices.Contains(versions, version) {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, apperror.PreconditionFailed("If-Match does not match the current version")
+	}
+
+	return versions, nil
+}
+
+// write runs fn with each listed version until one matches the stored row, or once with version 0
+// when any version may be changed. Repository writes are conditioned on the version, so a version
+// that does not match changes nothing.
+func (m ifMatch) write(fn func(version int64) error) error {
+	if len(m) == 0 {
+		return fn(0)
+	}
+
+	var err error
+	for _, version := range m {
+		err = fn(version)
+		if !errors.Is(err, utils.ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
+}
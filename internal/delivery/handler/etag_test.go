@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    ifMatch
+		wantErr bool
+	}{
+		{name: "no header", ifMatch: "", want: nil},
+		{name: "any version", ifMatch: "*", want: nil},
+		{name: "strong etag", ifMatch: `"3"`, want: ifMatch{3}},
+		{name: "weak etag never matches", ifMatch: `W/"3"`, wantErr: true},
+		{name: "unquoted", ifMatch: "3", wantErr: true},
+		{name: "list of etags", ifMatch: `"3", "4"`, want: ifMatch{3, 4}},
+		{name: "list skips etags that cannot match", ifMatch: `W/"2", "3",x, "3"`, want: ifMatch{3}},
+		{name: "list with any version", ifMatch: `"3", *`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got ifMatch
+			var gotErr error
+			app.Put("/", func(c *fiber.Ctx) error {
+				got, gotErr = ifMatchVersions(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(fiber.MethodPut, "/", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, tt.ifMatch)
+			}
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+
+			if (gotErr != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatchVersions() = %v, %v, want %v, error %v", got, gotErr, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIfMatch_write(t *testing.T) {
+	const stored = 4
+
+	tests := []struct {
+		name      string
+		versions  ifMatch
+		wantTried []int64
+		wantErr   error
+	}{
+		{name: "any version", versions: nil, wantTried: []int64{0}},
+		{name: "listed version matches", versions: ifMatch{3, 4, 5}, wantTried: []int64{3, 4}},
+		{name: "no listed version matches", versions: ifMatch{2, 3}, wantTried: []int64{2, 3}, wantErr: utils.ErrVersionConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tried []int64
+			err := tt.versions.write(func(version int64) error {
+				tried = append(tried, version)
+				if version != 0 && version != stored {
+					return utils.ErrVersionConflict
+				}
+				return nil
+			})
+
+			if err != tt.wantErr || !reflect.DeepEqual(tried, tt.wantTried) {
+				t.Errorf("write() tried %v, error %v, want %v, error %v", tried, err, tt.wantTried, tt.wantErr)
+			}
+		})
+	}
+}
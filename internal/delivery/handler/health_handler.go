@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/MCPutro/go-management-project/internal/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler interface {
+	Liveness(c *fiber.Ctx) error
+	Readiness(c *fiber.Ctx) error
+}
+
+type healthHandler struct {
+	health *health.Health
+}
+
+func NewHealthHandler(health *health.Health) HealthHandler {
+	return &healthHandler{health: health}
+}
+
+// Liveness only tells that the process can serve requests; dependencies are not checked so a
+// database outage does not make the orchestrator restart every instance.
+func (h *healthHandler) Liveness(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(health.Report{Status: health.StatusUp})
+}
+
+// Readiness returns the status and latency of each dependency from the last background check;
+// why a dependency failed is logged, not sent to whoever can reach the endpoint.
+func (h *healthHandler) Readiness(c *fiber.Ctx) error {
+	report := h.health.Last()
+
+	status := fiber.StatusOK
+	if report.Status != health.StatusUp {
+		status = fiber.StatusServiceUnavailable
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(status).JSON(report)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: connection refused") }
+
+	tests := []struct {
+		name         string
+		check        health.CheckFunc
+		wantStatus   int
+		wantReport   string
+		wantDatabase string
+		wantReplica  string
+		shuttingDown bool
+		notRefreshed bool
+	}{
+		{name: "ready", check: up, wantStatus: fiber.StatusOK, wantReport: health.StatusUp, wantDatabase: health.StatusUp, wantReplica: health.StatusDown},
+		{name: "database down", check: down, wantStatus: fiber.StatusServiceUnavailable, wantReport: health.StatusDown, wantDatabase: health.StatusDown, wantReplica: health.StatusDown},
+		{name: "shutting down", check: up, shuttingDown: true, wantStatus: fiber.StatusServiceUnavailable, wantReport: health.StatusDown, wantDatabase: health.StatusUp, wantReplica: health.StatusDown},
+		{name: "not checked yet", check: up, notRefreshed: true, wantStatus: fiber.StatusServiceUnavailable, wantReport: health.StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.NewHealth(50 * time.Millisecond)
+			h.Register("database", tt.check)
+			h.RegisterInformational("database_replica", down)
+			if !tt.notRefreshed {
+				h.Refresh(context.Background())
+			}
+			if tt.shuttingDown {
+				h.MarkShuttingDown()
+			}
+
+			app := fiber.New()
+			app.Get("/ready", NewHealthHandler(h).Readiness)
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/ready", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			var body struct {
+				Status string                    `json:"status"`
+				Checks map[string]map[string]any `json:"checks"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Status != tt.wantReport {
+				t.Errorf("report status = %q, want %q", body.Status, tt.wantReport)
+			}
+			for name, want := range map[string]string{"database": tt.wantDatabase, "database_replica": tt.wantReplica} {
+				check, ok := body.Checks[name]
+				if want == "" {
+					if ok {
+						t.Errorf("check %s = %v, want none before the first refresh", name, check)
+					}
+					continue
+				}
+				if check["status"] != want {
+					t.Errorf("check %s status = %v, want %s", name, check["status"], want)
+				}
+				if _, ok := check["latency_ms"].(float64); !ok {
+					t.Errorf("check %s latency_ms = %v, want a number", name, check["latency_ms"])
+				}
+				if _, ok := check["error"]; ok {
+					t.Errorf("check %s shows its error %v", name, check["error"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler interface {
+	GetJWKS(c *fiber.Ctx) error
+}
+
+type jwksHandler struct {
+	jwtService service.JWTService
+}
+
+func NewJWKSHandler(jwtService service.JWTService) JWKSHandler {
+	return &jwksHandler{jwtService: jwtService}
+}
+
+func (h *jwksHandler) GetJWKS(c *fiber.Ctx) error {
+	// cache singkat supaya key baru hasil rotasi cepat terlihat oleh verifier
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.jwtService.JWKS())
+}
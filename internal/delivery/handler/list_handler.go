@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+type ListHandler interface {
+	CreateList(c *fiber.Ctx) error
+	GetList(c *fiber.Ctx) error
+	GetListsByProject(c *fiber.Ctx) error
+	UpdateList(c *fiber.Ctx) error
+	PatchList(c *fiber.Ctx) error
+	DeleteList(c *fiber.Ctx) error
+}
+
+type listHandler struct {
+	listUsecase usecase.ListUsecase
+}
+
+func NewListHandler(listUsecase usecase.ListUsecase) ListHandler {
+	return &listHandler{listUsecase: listUsecase}
+}
+
+func (h *listHandler) CreateList(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	var req dto.CreateListRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	list := req.ToModel(principal.UserID)
+	if err := h.listUsecase.CreateList(ctx, list); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Project not found")
+		}
+		return err
+	}
+
+	setETag(c, list.Version)
+	return c.Status(fiber.StatusCreated).JSON(dto.NewListResponse(list))
+}
+
+func (h *listHandler) GetList(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid list ID format")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	list, err := h.listUsecase.GetListByID(ctx, id, principal.UserID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("List not found")
+		}
+		return err
+	}
+
+	setETag(c, list.Version)
+	return c.JSON(dto.NewListResponse(list))
+}
+
+func (h *listHandler) GetListsByProject(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	projectID, err := strconv.ParseInt(c.Params("project_id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid project ID format")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	lists, err := h.listUsecase.GetListsByProjectID(ctx, projectID, principal.UserID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Project not found")
+		}
+		return err
+	}
+
+	// project tanpa list tetap dikembalikan sebagai array kosong
+	return c.JSON(dto.NewListResponses(lists))
+}
+
+func (h *listHandler) UpdateList(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid list ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateListRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	list := req.ToModel(id, principal.UserID)
+	err = versions.write(func(version int64) error {
+		list.Version = version
+		return h.listUsecase.UpdateList(ctx, list)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("List not found")
+		}
+		return err
+	}
+
+	setETag(c, list.Version)
+	return c.JSON(dto.NewListResponse(list))
+}
+
+func (h *listHandler) PatchList(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid list ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PatchListRequest
+	if err := parseMergePatch(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	patch := req.ToModel(principal.UserID)
+	var list *model.List
+	err = versions.write(func(version int64) (err error) {
+		patch.Version = version
+		list, err = h.listUsecase.PatchList(ctx, id, patch)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("List not found")
+		}
+		return err
+	}
+
+	setETag(c, list.Version)
+	return c.JSON(dto.NewListResponse(list))
+}
+
+func (h *listHandler) DeleteList(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid list ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	err = versions.write(func(version int64) error {
+		return h.listUsecase.DeleteList(ctx, id, principal.UserID, version)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("List not found")
+		}
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
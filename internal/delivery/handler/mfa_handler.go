@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+type MFAHandler interface {
+	Enroll(c *fiber.Ctx) error
+	Confirm(c *fiber.Ctx) error
+	Disable(c *fiber.Ctx) error
+}
+
+type mfaHandler struct {
+	mfaUsecase usecase.MFAUsecase
+}
+
+func NewMFAHandler(mfaUsecase usecase.MFAUsecase) MFAHandler {
+	return &mfaHandler{mfaUsecase: mfaUsecase}
+}
+
+// interactivePrincipal returns the caller only when it signed in with a JWT; 2FA settings must
+// not be changed with a personal access token.
+func interactivePrincipal(c *fiber.Ctx) (*model.Principal, error) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return nil, apperror.Unauthorized("Authentication required")
+	}
+	if principal.AuthMethod != model.AuthMethodJWT {
+		return nil, apperror.Forbidden("Two-factor settings require an interactive login")
+	}
+	return principal, nil
+}
+
+func (h *mfaHandler) Enroll(c *fiber.Ctx) error {
+	principal, err := interactivePrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	enrollment, err := h.mfaUsecase.Enroll(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(enrollment)
+}
+
+func (h *mfaHandler) Confirm(c *fiber.Ctx) error {
+	principal, err := interactivePrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.ConfirmMFARequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	recoveryCodes, err := h.mfaUsecase.Confirm(ctx, principal.UserID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (h *mfaHandler) Disable(c *fiber.Ctx) error {
+	principal, err := interactivePrincipal(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.DisableMFARequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	err = h.mfaUsecase.Disable(ctx, principal.UserID, req.Password, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"time"
+)
+
+const oidcStateCookie = "oidc_state"
+
+// oidcProviderErrors are the error codes of an authorization response (OpenID Connect Core 3.1.2.6
+// and RFC 6749 4.1.2.1) passed on to the client; anything else becomes identity_provider_error.
+var oidcProviderErrors = map[string]bool{
+	"access_denied":              true,
+	"login_required":             true,
+	"consent_required":           true,
+	"interaction_required":       true,
+	"account_selection_required": true,
+	"temporarily_unavailable":    true,
+	"server_error":               true,
+}
+
+type OIDCHandler interface {
+	Login(c *fiber.Ctx) error
+	Callback(c *fiber.Ctx) error
+}
+
+type oidcHandler struct {
+	oidcService service.OIDCService
+	authUsecase usecase.AuthUsecase
+}
+
+func NewOIDCHandler(oidcService service.OIDCService, authUsecase usecase.AuthUsecase) OIDCHandler {
+	return &oidcHandler{oidcService: oidcService, authUsecase: authUsecase}
+}
+
+func (h *oidcHandler) Login(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	authURL, stateCookie, err := h.oidcService.Begin(ctx)
+	if err != nil {
+		return apperror.Unavailable("Identity provider is unavailable").Wrap(err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateCookie,
+		Path:     "/auth/oidc",
+		Expires:  time.Now().Add(h.oidcService.StateTTL()),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+func (h *oidcHandler) Callback(c *fiber.Ctx) error {
+	// state cookie hanya boleh dipakai sekali; ClearCookie tidak mengirim Path sehingga cookie tidak terhapus
+	stateCookie := c.Cookies(oidcStateCookie)
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/auth/oidc",
+		Expires:  time.Unix(0, 0),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	if providerError := c.Query("error"); providerError != "" {
+		// query bisa diisi siapa saja, jadi hanya kode yang dikenal yang diteruskan ke client
+		code := "identity_provider_error"
+		if oidcProviderErrors[providerError] {
+			code = providerError
+		}
+		slog.WarnContext(c.UserContext(), "oidc login rejected by the provider",
+			slog.String("error", providerError), slog.String("error_description", c.Query("error_description")))
+		return apperror.Unauthorized("Login was rejected by the identity provider").WithCode(code)
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 15*time.Second)
+	defer cancel()
+
+	identity, err := h.oidcService.Complete(ctx, stateCookie, c.Query("state"), c.Query("code"))
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidToken) {
+			// detail dari provider hanya untuk log, client cukup tahu login gagal
+			slog.WarnContext(ctx, "oidc callback rejected", slog.Any("error", err))
+			return apperror.Unauthorized("Login with the identity provider failed").WithCode("invalid_token").Wrap(err)
+		}
+		return apperror.Unavailable("Identity provider is unavailable").Wrap(err)
+	}
+
+	tokenPair, challenge, err := h.authUsecase.LoginWithExternalIdentity(ctx, identity, h.oidcService.AutoProvision())
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCredentials) {
+			return apperror.Forbidden(err.Error()).Wrap(err)
+		}
+		return err
+	}
+	if challenge != nil {
+		return c.JSON(challenge)
+	}
+
+	return c.JSON(tokenPair)
+}
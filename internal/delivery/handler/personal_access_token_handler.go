@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+type PersonalAccessTokenHandler interface {
+	CreateToken(c *fiber.Ctx) error
+	GetTokens(c *fiber.Ctx) error
+	RevokeToken(c *fiber.Ctx) error
+}
+
+type personalAccessTokenHandler struct {
+	tokenUsecase usecase.PersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenHandler(tokenUsecase usecase.PersonalAccessTokenUsecase) PersonalAccessTokenHandler {
+	return &personalAccessTokenHandler{tokenUsecase: tokenUsecase}
+}
+
+func (h *personalAccessTokenHandler) CreateToken(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+	// token baru hanya bisa dibuat dari sesi login interaktif
+	if principal.AuthMethod != model.AuthMethodJWT {
+		return apperror.Forbidden("Personal access tokens cannot create other tokens")
+	}
+
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	token := req.ToModel(principal.UserID)
+	plaintext, err := h.tokenUsecase.CreateToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	// plaintext token hanya ditampilkan sekali
+	return c.Status(fiber.StatusCreated).JSON(dto.CreatedPersonalAccessTokenResponse{
+		Token:   plaintext,
+		Details: dto.NewPersonalAccessTokenResponse(token),
+	})
+}
+
+func (h *personalAccessTokenHandler) GetTokens(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	tokens, err := h.tokenUsecase.GetTokensByUserID(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(dto.NewPersonalAccessTokenResponses(tokens))
+}
+
+func (h *personalAccessTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid token ID format")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	if err := h.tokenUsecase.RevokeToken(ctx, id, principal.UserID); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Token not found")
+		}
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+type ProjectHandler interface {
+	CreateProject(c *fiber.Ctx) error
+	GetProject(c *fiber.Ctx) error
+	UpdateProject(c *fiber.Ctx) error
+	PatchProject(c *fiber.Ctx) error
+	DeleteProject(c *fiber.Ctx) error
+}
+
+type projectHandler struct {
+	projectUsecase usecase.ProjectUsecase
+}
+
+func NewProjectHandler(projectUsecase usecase.ProjectUsecase) ProjectHandler {
+	return &projectHandler{projectUsecase: projectUsecase}
+}
+
+func (h *projectHandler) CreateProject(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	var req dto.CreateProjectRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	project := req.ToModel(principal.UserID)
+	if err := h.projectUsecase.CreateProject(ctx, project); err != nil {
+		return err
+	}
+
+	setETag(c, project.Version)
+	return c.Status(fiber.StatusCreated).JSON(dto.NewProjectResponse(project))
+}
+
+func (h *projectHandler) GetProject(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid project ID format")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	project, err := h.projectUsecase.GetProjectByID(ctx, id, principal.UserID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Project not found")
+		}
+		return err
+	}
+
+	setETag(c, project.Version)
+	return c.JSON(dto.NewProjectResponse(project))
+}
+
+func (h *projectHandler) UpdateProject(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid project ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateProjectRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	project := req.ToModel(id, principal.UserID)
+	err = versions.write(func(version int64) error {
+		project.Version = version
+		return h.projectUsecase.UpdateProject(ctx, project)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Project not found")
+		}
+		return err
+	}
+
+	setETag(c, project.Version)
+	return c.JSON(dto.NewProjectResponse(project))
+}
+
+func (h *projectHandler) PatchProject(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid project ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PatchProjectRequest
+	if err := parseMergePatch(c, &req); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	patch := req.ToModel(principal.UserID)
+	var project *model.Project
+	err = versions.write(func(version int64) (err error) {
+		patch.Version = version
+		project, err = h.projectUsecase.PatchProject(ctx, id, patch)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Project not found")
+		}
+		return err
+	}
+
+	setETag(c, project.Version)
+	return c.JSON(dto.NewProjectResponse(project))
+}
+
+func (h *projectHandler) DeleteProject(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return apperror.Unauthorized("Authentication required")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.Validation("Invalid project ID format")
+	}
+
+	versions, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 10*time.Second)
+	defer cancel()
+
+	err = versions.write(func(version int64) error {
+		return h.projectUsecase.DeleteProject(ctx, id, principal.UserID, version)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.NotFound("Project not found")
+		}
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/delivery/dto"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+// parseBody decodes the JSON body into a request DTO and runs its validate tags.
+func parseBody(c *fiber.Ctx, request interface{}) error {
+	if err := c.BodyParser(request); err != nil {
+		return apperror.Validation("Invalid input, cannot parse JSON")
+	}
+	return dto.Validate(request)
+}
+
+// parseMergePatch decodes a PATCH body as an RFC 7396 merge patch. Plain application/json is
+// accepted too, since a merge patch is a JSON object either way.
+func parseMergePatch(c *fiber.Ctx, patch interface{}) error {
+	mediaType, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case dto.MergePatchContentType, fiber.MIMEApplicationJSON:
+	default:
+		c.Set("Accept-Patch", dto.MergePatchContentType)
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, "PATCH requires "+dto.MergePatchContentType)
+	}
+	return dto.DecodeMergePatch(c.Body(), patch)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/middleware"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+type fakeUserUsecase struct {
+	usecase.UserUsecase
+	created []*model.User
+	deleted []int64
+}
+
+func (f *fakeUserUsecase) CreateUser(_ context.Context, user *model.User) error {
+	user.ID = int64(len(f.created) + 100)
+	user.Version = 1
+	f.created = append(f.created, user)
+	return nil
+}
+
+func (f *fakeUserUsecase) DeleteUser(_ context.Context, id, _, _ int64) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestUserHandler_DeleteUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *model.Principal
+		wantStatus int
+	}{
+		{name: "own account", principal: &model.Principal{UserID: 7}, wantStatus: fiber.StatusNoContent},
+		{name: "other account", principal: &model.Principal{UserID: 8}, wantStatus: fiber.StatusForbidden},
+		{name: "admin", principal: &model.Principal{UserID: 1, Roles: []string{model.RoleAdmin}, AMR: []string{model.AMRMFA}}, wantStatus: fiber.StatusNoContent},
+		{name: "admin without mfa", principal: &model.Principal{UserID: 1, Roles: []string{model.RoleAdmin}}, wantStatus: fiber.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userUsecase := &fakeUserUsecase{}
+			app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+			app.Delete("/users/:id", func(c *fiber.Ctx) error {
+				c.SetUserContext(middleware.WithPrincipal(c.UserContext(), tt.principal))
+				return c.Next()
+			}, NewUserHandler(userUsecase).DeleteUser)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/users/7", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if deleted := len(userUsecase.deleted) == 1; deleted != (tt.wantStatus == fiber.StatusNoContent) {
+				t.Errorf("deleted users = %v", userUsecase.deleted)
+			}
+		})
+	}
+}
+
+func TestUserHandler_CreateUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *model.Principal
+		wantStatus int
+	}{
+		{name: "user", principal: &model.Principal{UserID: 7}, wantStatus: fiber.StatusForbidden},
+		{name: "admin", principal: &model.Principal{UserID: 1, Roles: []string{model.RoleAdmin}, AMR: []string{model.AMRMFA}}, wantStatus: fiber.StatusCreated},
+		{name: "admin without mfa", principal: &model.Principal{UserID: 1, Roles: []string{model.RoleAdmin}}, wantStatus: fiber.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userUsecase := &fakeUserUsecase{}
+			app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+			app.Post("/users", func(c *fiber.Ctx) error {
+				c.SetUserContext(middleware.WithPrincipal(c.UserContext(), tt.principal))
+				return c.Next()
+			}, NewUserHandler(userUsecase).CreateUser)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(`{"name":"Jane","email":"jane@example.com"}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if created := len(userUsecase.created) == 1; created != (tt.wantStatus == fiber.StatusCreated) {
+				t.Errorf("created users = %v", userUsecase.created)
+			}
+		})
+	}
+}
@@ -2,47 +2,119 @@ package router
 
 import (
 	"github.com/MCPutro/go-management-project/internal/delivery/handler"
+	"github.com/MCPutro/go-management-project/internal/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 // RegisterUserRoutes registers all user-related routes
-func RegisterUserRoutes(router fiber.Router, handler handler.UserHandler) {
+func RegisterUserRoutes(router fiber.Router, handler handler.UserHandler, auth, rateLimit fiber.Handler) {
 	users := router.Group("/users")
+	scope := middleware.RequireMethodScope()
 
-	users.Post("/", handler.CreateUser)
-	users.Get("/:id", handler.GetUser)
-	users.Put("/:id", handler.UpdateUser)
-	users.Delete("/:id", handler.DeleteUser)
-}
-
-//// registerProjectRoutes registers all project-related routes
-//func registerProjectRoutes(router fiber.Router, handler *Handler) {
-//	projects := router.Group("/projects")
-//
-//	projects.Post("/", handler.CreateProject)
-//	projects.Get("/:id", handler.GetProject)
-//	projects.Put("/:id", handler.UpdateProject)
-//	projects.Delete("/:id", handler.DeleteProject)
-//}
-//
-//// registerListRoutes registers all list-related routes
-//func registerListRoutes(router fiber.Router, handler *Handler) {
-//	lists := router.Group("/lists")
-//
-//	lists.Post("/", handler.CreateList)
-//	lists.Get("/:id", handler.GetList)
-//	lists.Get("/project/:project_id", handler.GetListsByProject)
-//	lists.Put("/:id", handler.UpdateList)
-//	lists.Delete("/:id", handler.DeleteList)
-//}
-//
-//// registerCardRoutes registers all card-related routes
-//func registerCardRoutes(router fiber.Router, handler *Handler) {
-//	cards := router.Group("/cards")
-//
-//	cards.Post("/", handler.CreateCard)
-//	cards.Get("/:id", handler.GetCard)
-//	cards.Get("/list/:list_id", handler.GetCardsByList)
-//	cards.Put("/:id", handler.UpdateCard)
-//	cards.Delete("/:id", handler.DeleteCard)
-//}
+	// middleware dipasang per route karena prefix /users juga dipakai /users/me/tokens
+	users.Post("/", auth, rateLimit, scope, handler.CreateUser)
+	users.Get("/:id", auth, rateLimit, scope, handler.GetUser)
+	users.Put("/:id", auth, rateLimit, scope, handler.UpdateUser)
+	users.Patch("/:id", auth, rateLimit, scope, handler.PatchUser)
+	users.Delete("/:id", auth, rateLimit, scope, handler.DeleteUser)
+}
+
+// RegisterAuthRoutes registers all authentication routes
+func RegisterAuthRoutes(router fiber.Router, handler handler.AuthHandler, jwtAuth, rateLimit fiber.Handler) {
+	auth := router.Group("/auth")
+
+	auth.Post("/register", rateLimit, handler.Register)
+	auth.Post("/login", rateLimit, handler.Login)
+	auth.Post("/login/mfa", rateLimit, handler.LoginMFA)
+	auth.Post("/refresh", rateLimit, handler.Refresh)
+	auth.Post("/logout", jwtAuth, rateLimit, handler.Logout)
+}
+
+// RegisterAccountRoutes registers the email verification and password reset routes
+func RegisterAccountRoutes(router fiber.Router, handler handler.AccountHandler, jwtAuth, rateLimit fiber.Handler) {
+	auth := router.Group("/auth")
+
+	auth.Post("/email/verification", jwtAuth, rateLimit, handler.RequestEmailVerification)
+	auth.Post("/email/verify", rateLimit, handler.VerifyEmail)
+	auth.Post("/password/forgot", rateLimit, handler.ForgotPassword)
+	auth.Post("/password/reset", rateLimit, handler.ResetPassword)
+}
+
+// RegisterMFARoutes registers the two-factor enrollment routes of the current user
+func RegisterMFARoutes(router fiber.Router, handler handler.MFAHandler, jwtAuth, rateLimit fiber.Handler) {
+	mfa := router.Group("/auth/mfa", jwtAuth, rateLimit)
+
+	mfa.Post("/enroll", handler.Enroll)
+	mfa.Post("/confirm", handler.Confirm)
+	mfa.Post("/disable", handler.Disable)
+}
+
+// RegisterOIDCRoutes registers the single sign-on routes
+func RegisterOIDCRoutes(router fiber.Router, handler handler.OIDCHandler, rateLimit fiber.Handler) {
+	oidc := router.Group("/auth/oidc", rateLimit)
+
+	oidc.Get("/login", handler.Login)
+	oidc.Get("/callback", handler.Callback)
+}
+
+// RegisterPersonalAccessTokenRoutes registers the personal access token routes of the current user
+func RegisterPersonalAccessTokenRoutes(router fiber.Router, handler handler.PersonalAccessTokenHandler, auth, rateLimit fiber.Handler) {
+	tokens := router.Group("/users/me/tokens", auth, rateLimit, middleware.RequireMethodScope())
+
+	tokens.Post("/", handler.CreateToken)
+	tokens.Get("/", handler.GetTokens)
+	tokens.Delete("/:id", handler.RevokeToken)
+}
+
+// RegisterMetricsRoutes registers the Prometheus scrape endpoint
+func RegisterMetricsRoutes(router fiber.Router, path string, handler fiber.Handler) {
+	router.Get(path, handler)
+}
+
+// RegisterHealthRoutes registers the liveness and readiness probes
+func RegisterHealthRoutes(router fiber.Router, handler handler.HealthHandler) {
+	router.Get("/healthz", handler.Liveness)
+	router.Get("/readyz", handler.Readiness)
+}
+
+// RegisterWellKnownRoutes registers the /.well-known discovery routes
+func RegisterWellKnownRoutes(router fiber.Router, handler handler.JWKSHandler) {
+	wellKnown := router.Group("/.well-known")
+
+	wellKnown.Get("/jwks.json", handler.GetJWKS)
+}
+
+// RegisterProjectRoutes registers all project-related routes
+func RegisterProjectRoutes(router fiber.Router, handler handler.ProjectHandler, auth, rateLimit fiber.Handler) {
+	projects := router.Group("/projects", auth, rateLimit, middleware.RequireMethodScope())
+
+	projects.Post("/", handler.CreateProject)
+	projects.Get("/:id", handler.GetProject)
+	projects.Put("/:id", handler.UpdateProject)
+	projects.Patch("/:id", handler.PatchProject)
+	projects.Delete("/:id", handler.DeleteProject)
+}
+
+// RegisterListRoutes registers all list-related routes
+func RegisterListRoutes(router fiber.Router, handler handler.ListHandler, auth, rateLimit fiber.Handler) {
+	lists := router.Group("/lists", auth, rateLimit, middleware.RequireMethodScope())
+
+	lists.Post("/", handler.CreateList)
+	lists.Get("/:id", handler.GetList)
+	lists.Get("/project/:project_id", handler.GetListsByProject)
+	lists.Put("/:id", handler.UpdateList)
+	lists.Patch("/:id", handler.PatchList)
+	lists.Delete("/:id", handler.DeleteList)
+}
+
+// RegisterCardRoutes registers all card-related routes
+func RegisterCardRoutes(router fiber.Router, handler handler.CardHandler, auth, rateLimit fiber.Handler) {
+	cards := router.Group("/cards", auth, rateLimit, middleware.RequireMethodScope())
+
+	cards.Post("/", handler.CreateCard)
+	cards.Get("/:id", handler.GetCard)
+	cards.Get("/list/:list_id", handler.GetCardsByList)
+	cards.Put("/:id", handler.UpdateCard)
+	cards.Patch("/:id", handler.PatchCard)
+	cards.Delete("/:id", handler.DeleteCard)
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// DatabaseCheck pings the database with a connection from the pool.
+func DatabaseCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// MigrationCheck compares the version recorded by golang-migrate in schema_migrations with the
+// newest migration in migrations, so an instance is not ready while its schema is behind the code.
+func MigrationCheck(db *sql.DB, migrations fs.FS) CheckFunc {
+	return func(ctx context.Context) error {
+		latest, err := LatestMigration(migrations)
+		if err != nil {
+			return err
+		}
+
+		var version int64
+		var dirty bool
+		err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no migration applied, latest is %d", latest)
+		}
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		if version < latest {
+			return fmt.Errorf("database is at migration %d, latest is %d", version, latest)
+		}
+		return nil
+	}
+}
+
+// LatestMigration returns the highest version of the <version>_<name>.up.sql files in migrations.
+func LatestMigration(migrations fs.FS) (int64, error) {
+	files, err := fs.Glob(migrations, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, file := range files {
+		prefix, _, ok := strings.Cut(file, "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	if latest == 0 {
+		return 0, errors.New("no migration found")
+	}
+	return latest, nil
+}
@@ -0,0 +1,177 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc reports whether a dependency is usable; a nil error means it is.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of one dependency check.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the readiness of the service and of each of its dependencies.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+	// informational checks are reported and logged but never make the service not ready
+	informational bool
+}
+
+// Health runs the registered dependency checks for the readiness endpoint. The checks run in the
+// background, so a probe only reads the last report and cannot be used to load the dependencies.
+type Health struct {
+	timeout      time.Duration
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+	last         atomic.Pointer[Report]
+}
+
+// NewHealth creates a Health whose checks each get at most timeout to finish.
+func NewHealth(timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Health{timeout: timeout}
+}
+
+// Register adds a dependency check. It must be called before the server starts.
+func (h *Health) Register(name string, check CheckFunc) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// RegisterInformational adds a check for a dependency the service can do without, such as the
+// read replica that falls back to the primary. A failure is logged but keeps the service ready.
+func (h *Health) RegisterInformational(name string, check CheckFunc) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check, informational: true})
+}
+
+// MarkShuttingDown makes the service not ready, so load balancers stop sending new requests
+// while in-flight requests are drained.
+func (h *Health) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *Health) ShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
+
+// Status returns the status of the last report; the service is down until the first report and
+// as soon as it is shutting down.
+func (h *Health) Status() string {
+	return h.Last().Status
+}
+
+// Last returns the last report with the status and latency of each check. The error messages are
+// left out since they can reveal hosts and credentials; Refresh logs them instead.
+func (h *Health) Last() Report {
+	last := h.last.Load()
+	if last == nil {
+		return Report{Status: StatusDown}
+	}
+
+	report := Report{Status: last.Status, Checks: make(map[string]CheckResult, len(last.Checks))}
+	for name, result := range last.Checks {
+		result.Error = ""
+		report.Checks[name] = result
+	}
+	if h.ShuttingDown() {
+		report.Status = StatusDown
+		report.Checks["shutdown"] = CheckResult{Status: StatusDown}
+	}
+	return report
+}
+
+// Run refreshes the report every interval until ctx is done.
+func (h *Health) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh runs the checks and keeps the report for Status and Last. Why a check failed is only
+// logged, the readiness endpoint shows its status and latency.
+func (h *Health) Refresh(ctx context.Context) {
+	report := h.Ready(ctx)
+	previous := h.last.Swap(&report)
+
+	for name, result := range report.Checks {
+		if result.Status != StatusUp {
+			slog.WarnContext(ctx, "readiness check failed", slog.String("check", name), slog.String("error", result.Error))
+		}
+	}
+	if report.Status == StatusUp && previous != nil && previous.Status != StatusUp {
+		slog.InfoContext(ctx, "readiness checks passed again")
+	}
+}
+
+// Ready runs all checks concurrently. The service is up only when every check that is not
+// informational passed and it is not shutting down.
+func (h *Health) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(h.checks))}
+	if h.ShuttingDown() {
+		report.Status = StatusDown
+		report.Checks["shutdown"] = CheckResult{Status: StatusDown, Error: "service is shutting down"}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			result := h.run(ctx, c.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status != StatusUp && !c.informational {
+				report.Status = StatusDown
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (h *Health) run(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{Status: StatusUp, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
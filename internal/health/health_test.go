@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/MCPutro/go-management-project/migration"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name          string
+		checks        map[string]CheckFunc
+		informational map[string]CheckFunc
+		shuttingDown  bool
+		wantStatus    string
+		wantDown      []string
+	}{
+		{name: "all up", checks: map[string]CheckFunc{"database": up, "migrations": up}, wantStatus: StatusUp},
+		{name: "one down", checks: map[string]CheckFunc{"database": down, "migrations": up}, wantStatus: StatusDown, wantDown: []string{"database"}},
+		{name: "check times out", checks: map[string]CheckFunc{"database": slow}, wantStatus: StatusDown, wantDown: []string{"database"}},
+		{name: "informational down", checks: map[string]CheckFunc{"database": up}, informational: map[string]CheckFunc{"database_replica": down}, wantStatus: StatusUp, wantDown: []string{"database_replica"}},
+		{name: "shutting down", checks: map[string]CheckFunc{"database": up}, shuttingDown: true, wantStatus: StatusDown, wantDown: []string{"shutdown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealth(50 * time.Millisecond)
+			for name, check := range tt.checks {
+				h.Register(name, check)
+			}
+			for name, check := range tt.informational {
+				h.RegisterInformational(name, check)
+			}
+			if tt.shuttingDown {
+				h.MarkShuttingDown()
+			}
+
+			report := h.Ready(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Ready() status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			for _, name := range tt.wantDown {
+				if result := report.Checks[name]; result.Status != StatusDown || result.Error == "" {
+					t.Errorf("check %s = %+v, want down with an error", name, result)
+				}
+			}
+		})
+	}
+}
+
+func TestHealth_Status(t *testing.T) {
+	var err error
+	h := NewHealth(50 * time.Millisecond)
+	h.Register("database", func(ctx context.Context) error { return err })
+
+	if got := h.Status(); got != StatusDown {
+		t.Errorf("Status() before the first refresh = %s, want %s", got, StatusDown)
+	}
+
+	h.Refresh(context.Background())
+	if got := h.Status(); got != StatusUp {
+		t.Errorf("Status() after a passing refresh = %s, want %s", got, StatusUp)
+	}
+
+	err = errors.New("connection refused")
+	h.Refresh(context.Background())
+	if got := h.Status(); got != StatusDown {
+		t.Errorf("Status() after a failing refresh = %s, want %s", got, StatusDown)
+	}
+
+	err = nil
+	h.Refresh(context.Background())
+	h.MarkShuttingDown()
+	if got := h.Status(); got != StatusDown {
+		t.Errorf("Status() while shutting down = %s, want %s", got, StatusDown)
+	}
+}
+
+func TestLatestMigration(t *testing.T) {
+	migrations := fstest.MapFS{
+		"000001_create_auth_tokens_table.up.sql":   {},
+		"000001_create_auth_tokens_table.down.sql": {},
+		"000012_add_version_columns.up.sql":        {},
+		"000013_add_index.down.sql":                {},
+		"schema.sql":                               {},
+	}
+
+	latest, err := LatestMigration(migrations)
+	if err != nil || latest != 12 {
+		t.Errorf("LatestMigration() = %d, %v, want 12", latest, err)
+	}
+
+	if _, err := LatestMigration(fstest.MapFS{}); err == nil {
+		t.Error("LatestMigration() without migrations must fail")
+	}
+}
+
+func TestLatestMigration_Embedded(t *testing.T) {
+	latest, err := LatestMigration(migration.Files)
+	if err != nil || latest < 11 {
+		t.Errorf("LatestMigration(migration.Files) = %d, %v, want the embedded migrations", latest, err)
+	}
+}
@@ -0,0 +1,119 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle runs the server and the background workers of the service and stops them on
+// SIGINT/SIGTERM. Shutdown hooks run in reverse registration order, like defer, so a component
+// is stopped before the components it was built on (server before workers before database).
+type Lifecycle struct {
+	timeout time.Duration
+	hooks   []hook
+
+	workerCtx    context.Context
+	cancelWorker context.CancelFunc
+	workers      sync.WaitGroup
+}
+
+// New creates a Lifecycle whose whole shutdown must finish within timeout.
+func New(timeout time.Duration) *Lifecycle {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	workerCtx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{timeout: timeout, workerCtx: workerCtx, cancelWorker: cancel}
+}
+
+// OnShutdown registers a hook run at shutdown. Hooks still run after the timeout passed, with an
+// expired context, so resources such as the database pool are always released.
+func (l *Lifecycle) OnShutdown(name string, stop func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, hook{name: name, stop: stop})
+}
+
+// Go starts a background worker. Its context is cancelled by StopWorkers, which then waits for it.
+func (l *Lifecycle) Go(name string, worker func(ctx context.Context)) {
+	l.workers.Add(1)
+	go func() {
+		defer l.workers.Done()
+		worker(l.workerCtx)
+		slog.Debug("worker stopped", slog.String("worker", name))
+	}()
+}
+
+// StopWorkers cancels the workers started with Go and waits until they return or ctx is done.
+// Register it with OnShutdown at the point the workers have to be stopped.
+func (l *Lifecycle) StopWorkers(ctx context.Context) error {
+	l.cancelWorker()
+
+	done := make(chan struct{})
+	go func() {
+		l.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers did not stop: %w", ctx.Err())
+	}
+}
+
+// Run calls serve and blocks until ctx is done, SIGINT/SIGTERM arrives or serve returns, then
+// runs the shutdown hooks. serve must return once the server hook has stopped the server.
+func (l *Lifecycle) Run(ctx context.Context, serve func() error) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down", slog.Duration("timeout", l.timeout))
+	case err = <-served:
+		// server berhenti sendiri (misal port sudah dipakai), worker dan database tetap dibereskan
+		slog.Error("server stopped unexpectedly", slog.Any("error", err))
+	}
+	// sinyal berikutnya langsung menghentikan proses
+	stop()
+
+	return errors.Join(err, l.shutdown())
+}
+
+func (l *Lifecycle) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		h := l.hooks[i]
+
+		start := time.Now()
+		if err := h.stop(ctx); err != nil {
+			slog.Error("shutdown step failed", slog.String("step", h.name), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		slog.Info("shutdown step done", slog.String("step", h.name), slog.Duration("duration", time.Since(start)))
+	}
+
+	return errors.Join(errs...)
+}
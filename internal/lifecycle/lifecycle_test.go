@@ -0,0 +1,88 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLifecycle_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		serveErr   error
+		hookErr    error
+		wantErr    bool
+		wantCalled []string
+	}{
+		{name: "shutdown in reverse order", wantCalled: []string{"server", "workers", "database"}},
+		{name: "server fails to start", serveErr: errors.New("address already in use"), wantErr: true, wantCalled: []string{"server", "workers", "database"}},
+		{name: "failing hook does not stop the others", hookErr: errors.New("close failed"), wantErr: true, wantCalled: []string{"server", "workers", "database"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(time.Second)
+
+			workerStopped := make(chan struct{})
+			l.Go("ticker", func(ctx context.Context) {
+				<-ctx.Done()
+				close(workerStopped)
+			})
+
+			var called []string
+			stopped := make(chan struct{})
+			l.OnShutdown("database", func(ctx context.Context) error {
+				called = append(called, "database")
+				return tt.hookErr
+			})
+			l.OnShutdown("workers", func(ctx context.Context) error {
+				called = append(called, "workers")
+				return l.StopWorkers(ctx)
+			})
+			l.OnShutdown("server", func(ctx context.Context) error {
+				called = append(called, "server")
+				close(stopped)
+				return nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			serve := func() error {
+				if tt.serveErr != nil {
+					return tt.serveErr
+				}
+				cancel()
+				<-stopped
+				return nil
+			}
+
+			err := l.Run(ctx, serve)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(called, tt.wantCalled) {
+				t.Errorf("shutdown order = %v, want %v", called, tt.wantCalled)
+			}
+			select {
+			case <-workerStopped:
+			default:
+				t.Error("worker context was not cancelled")
+			}
+		})
+	}
+}
+
+func TestLifecycle_StopWorkersTimeout(t *testing.T) {
+	l := New(time.Second)
+	release := make(chan struct{})
+	defer close(release)
+	l.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.StopWorkers(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StopWorkers() error = %v, want deadline exceeded", err)
+	}
+}
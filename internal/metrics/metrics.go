@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "go_management"
+
+// Registry holds every metric of the service; it is exposed by Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	transactionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "usecase_transaction_duration_seconds",
+		Help:      "Time spent in the database transaction of a usecase method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"usecase", "method"})
+
+	ProjectsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "projects_created_total",
+		Help:      "Projects created.",
+	})
+
+	ListsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lists_created_total",
+		Help:      "Lists created.",
+	})
+
+	CardsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cards_created_total",
+		Help:      "Cards created.",
+	})
+
+	CardsMoved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cards_moved_total",
+		Help:      "Cards moved to another position.",
+	})
+
+	UsersRegistered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "Users registered with email and password.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		transactionDuration,
+		ProjectsCreated,
+		ListsCreated,
+		CardsCreated,
+		CardsMoved,
+		UsersRegistered,
+	)
+}
+
+// RegisterDB exposes the connection pool statistics of db, e.g. open, in use and wait count.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+type transactionKey struct{}
+
+type transaction struct {
+	usecase string
+	method  string
+}
+
+// WithTransaction labels the transactions run with ctx by the usecase method that runs them.
+func WithTransaction(ctx context.Context, usecase, method string) context.Context {
+	return context.WithValue(ctx, transactionKey{}, transaction{usecase: usecase, method: method})
+}
+
+// ObserveTransaction records the time of a transaction run with ctx, measured from start. A
+// transaction without WithTransaction labels is not recorded.
+// Use it as: defer metrics.ObserveTransaction(ctx, time.Now())
+func ObserveTransaction(ctx context.Context, start time.Time) {
+	if tx, ok := ctx.Value(transactionKey{}).(transaction); ok {
+		transactionDuration.WithLabelValues(tx.usecase, tx.method).Observe(time.Since(start).Seconds())
+	}
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
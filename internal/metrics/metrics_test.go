@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		route  string
+		status int
+	}{
+		{name: "success", method: http.MethodGet, route: "/cards/:id", status: http.StatusOK},
+		{name: "client error", method: http.MethodPatch, route: "/cards/:id", status: http.StatusPreconditionFailed},
+		{name: "unmatched route", method: http.MethodGet, route: "unmatched", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := httpRequests.WithLabelValues(tt.method, tt.route, strconv.Itoa(tt.status))
+			before := testutil.ToFloat64(counter)
+
+			ObserveHTTPRequest(tt.method, tt.route, tt.status, 10*time.Millisecond)
+
+			after := testutil.ToFloat64(counter)
+			if after-before != 1 {
+				t.Errorf("http_requests_total increased by %v, want 1", after-before)
+			}
+		})
+	}
+}
+
+func TestObserveTransaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantSeries int
+	}{
+		{name: "labelled", ctx: WithTransaction(context.Background(), "test", "Labelled"), wantSeries: 1},
+		{name: "not labelled", ctx: context.Background(), wantSeries: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.CollectAndCount(transactionDuration)
+
+			ObserveTransaction(tt.ctx, time.Now().Add(-10*time.Millisecond))
+
+			if got := testutil.CollectAndCount(transactionDuration) - before; got != tt.wantSeries {
+				t.Errorf("new usecase_transaction_duration_seconds series = %d, want %d", got, tt.wantSeries)
+			}
+		})
+	}
+}
+
+func TestRegisterDB(t *testing.T) {
+	// sql.Open belum membuka koneksi, statistik pool tetap tersedia
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+
+	if err := RegisterDB(db, "test"); err != nil {
+		t.Fatalf("RegisterDB() error = %v", err)
+	}
+	expected := `
+# HELP go_sql_max_open_connections Maximum number of open connections to the database.
+# TYPE go_sql_max_open_connections gauge
+go_sql_max_open_connections{db_name="test"} 7
+`
+	if err := testutil.GatherAndCompare(Registry, strings.NewReader(expected), "go_sql_max_open_connections"); err != nil {
+		t.Error(err)
+	}
+
+	if err := RegisterDB(db, "test"); err == nil {
+		t.Error("RegisterDB() registered the same database name twice")
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// CORS answers browser preflight requests. Allowed origins are read from the runtime config on
+// every request, so a reload of Cors.AllowOrigins applies without a restart.
+func CORS(runtime *config.Runtime) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOriginsFunc: func(origin string) bool {
+			return originAllowed(runtime.Current().Cors.AllowOrigins, origin)
+		},
+		AllowMethods: strings.Join([]string{
+			fiber.MethodGet, fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete,
+		}, ","),
+		AllowHeaders: strings.Join([]string{
+			fiber.HeaderAuthorization, fiber.HeaderContentType, fiber.HeaderIfMatch, fiber.HeaderXRequestID,
+		}, ","),
+		// header yang dibaca client: versi untuk If-Match, korelasi log, dan kuota rate limit
+		ExposeHeaders: strings.Join([]string{
+			fiber.HeaderETag, fiber.HeaderXRequestID, fiber.HeaderRetryAfter,
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+		}, ","),
+	})
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	for _, o := range allowed {
+		if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
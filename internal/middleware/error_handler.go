@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorHandler is the Fiber ErrorHandler. It writes every error returned by a handler as
+// application/problem+json, so handlers only return errors and never build error bodies.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var problem apperror.Problem
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		// error bawaan Fiber: route tidak ada, method salah, body terlalu besar, dll
+		problem = apperror.Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(fiberErr.Code),
+			Status:   fiberErr.Code,
+			Detail:   fiberErr.Message,
+			Instance: c.Path(),
+			Code:     strings.ReplaceAll(strings.ToLower(http.StatusText(fiberErr.Code)), " ", "_"),
+		}
+	} else {
+		appErr := apperror.From(err)
+		if appErr.Kind == apperror.KindInternal || appErr.Kind == apperror.KindUnavailable {
+			slog.ErrorContext(c.UserContext(), "request failed",
+				slog.String("method", c.Method()),
+				slog.String("path", c.Path()),
+				slog.Any("error", err),
+			)
+		}
+		problem = appErr.Problem(c.Path())
+	}
+	// request ID ikut dikirim supaya client bisa melaporkan error yang bisa dicari di log
+	problem.RequestID = applog.RequestIDFromContext(c.UserContext())
+
+	return c.Status(problem.Status).JSON(problem, apperror.ProblemContentType)
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorHandler(t *testing.T) {
+	cause := errors.New("pq: connection refused")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+		wantFields int
+	}{
+		{name: "internal", err: apperror.Internal(cause), wantStatus: fiber.StatusInternalServerError, wantCode: apperror.CodeInternal, wantDetail: "Internal server error"},
+		{name: "unknown error is internal", err: cause, wantStatus: fiber.StatusInternalServerError, wantCode: apperror.CodeInternal, wantDetail: "Internal server error"},
+		{name: "validation", err: apperror.Validation("Invalid input", apperror.FieldError{Field: "name", Message: "is required"}), wantStatus: fiber.StatusBadRequest, wantCode: apperror.CodeValidation, wantDetail: "Invalid input", wantFields: 1},
+		{name: "unauthorized", err: apperror.Unauthorized("Authentication required"), wantStatus: fiber.StatusUnauthorized, wantCode: apperror.CodeUnauthorized, wantDetail: "Authentication required"},
+		{name: "forbidden", err: apperror.Forbidden("Admin rights required"), wantStatus: fiber.StatusForbidden, wantCode: apperror.CodeForbidden, wantDetail: "Admin rights required"},
+		{name: "not found", err: apperror.NotFound("Project not found"), wantStatus: fiber.StatusNotFound, wantCode: apperror.CodeNotFound, wantDetail: "Project not found"},
+		{name: "conflict", err: apperror.Conflict("Email is already registered"), wantStatus: fiber.StatusConflict, wantCode: apperror.CodeConflict, wantDetail: "Email is already registered"},
+		{name: "precondition failed", err: apperror.PreconditionFailed("Version mismatch"), wantStatus: fiber.StatusPreconditionFailed, wantCode: apperror.CodePreconditionFailed, wantDetail: "Version mismatch"},
+		{name: "too many requests", err: apperror.TooManyRequests("Rate limit exceeded").WithCode("rate_limited"), wantStatus: fiber.StatusTooManyRequests, wantCode: "rate_limited", wantDetail: "Rate limit exceeded"},
+		{name: "unavailable", err: apperror.Unavailable("Identity provider is unavailable").Wrap(cause), wantStatus: fiber.StatusServiceUnavailable, wantCode: apperror.CodeUnavailable, wantDetail: "Identity provider is unavailable"},
+		{name: "fiber error", err: fiber.ErrRequestEntityTooLarge, wantStatus: fiber.StatusRequestEntityTooLarge, wantCode: "request_entity_too_large", wantDetail: fiber.ErrRequestEntityTooLarge.Message},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/projects", RequestID(), func(c *fiber.Ctx) error {
+				return tt.err
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/projects", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); got != apperror.ProblemContentType {
+				t.Errorf("content type = %q, want %q", got, apperror.ProblemContentType)
+			}
+
+			raw, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var problem apperror.Problem
+			if err := json.Unmarshal(raw, &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			if problem.Status != tt.wantStatus || problem.Code != tt.wantCode || problem.Detail != tt.wantDetail || len(problem.Errors) != tt.wantFields {
+				t.Errorf("problem = %+v, want status %d code %q detail %q with %d field errors", problem, tt.wantStatus, tt.wantCode, tt.wantDetail, tt.wantFields)
+			}
+			if problem.Instance != "/projects" || problem.RequestID == "" || problem.RequestID != resp.Header.Get(fiber.HeaderXRequestID) {
+				t.Errorf("problem instance = %q, request id = %q, want /projects and the X-Request-ID", problem.Instance, problem.RequestID)
+			}
+			// penyebab error hanya untuk log
+			if strings.Contains(string(raw), cause.Error()) {
+				t.Errorf("problem %s leaks the cause", raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests that matched no route, so random paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and latency of every request per route pattern (e.g. /cards/:id).
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status, route := responseStatus(c, err)
+		metrics.ObserveHTTPRequest(c.Method(), route, status, time.Since(start))
+		return err
+	}
+}
+
+// responseStatus returns the status the request ends with and its route pattern. Middlewares
+// registered after AccessLog see the error before ErrorHandler ran, so the status is derived
+// from the error the same way ErrorHandler does.
+func responseStatus(c *fiber.Ctx, err error) (int, string) {
+	route := c.Route().Path
+	if err == nil {
+		return c.Response().StatusCode(), route
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		if fiberErr.Code == fiber.StatusNotFound {
+			route = unmatchedRoute
+		}
+		return fiberErr.Code, route
+	}
+	return apperror.From(err).Status(), route
+}
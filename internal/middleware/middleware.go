@@ -3,61 +3,128 @@ package middleware
 import (
 	"context"
 	"errors"
-	"github.com/MCPutro/go-management-project/internal/config/constant"
+	"fmt"
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/utils"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 	"strings"
+	"time"
 )
 
-func JWTAuth(secret string) fiber.Handler {
+const authRealm = "go-management-project"
+
+// TokenRevocationChecker reports whether an access token was revoked before its expiry (e.g. on logout
+// or password reset).
+type TokenRevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, userID int64, tokenID string, issuedAt time.Time) (bool, error)
+}
+
+// PersonalAccessTokenAuthenticator resolves an opaque personal access token to its principal.
+type PersonalAccessTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*model.Principal, error)
+}
+
+// JWTAuth accepts either a JWT access token or a personal access token as bearer credential.
+func JWTAuth(jwtService service.JWTService, revocationChecker TokenRevocationChecker, patAuthenticator PersonalAccessTokenAuthenticator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get(fiber.HeaderAuthorization)
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authorization header required",
-			})
+			return unauthorized(c, "", "Authorization header required")
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
-		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token format",
-			})
+		if len(bearerToken) != 2 || !strings.EqualFold(bearerToken[0], "Bearer") {
+			return unauthorized(c, "invalid_request", "Invalid token format")
 		}
 
-		tokenString := bearerToken[1]
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
+		// JWT selalu terdiri dari tiga segmen, personal access token tidak mengandung titik
+		if strings.Count(bearerToken[1], ".") != 2 {
+			principal, err := patAuthenticator.Authenticate(c.UserContext(), bearerToken[1])
+			if errors.Is(err, utils.ErrInvalidToken) {
+				return unauthorized(c, "invalid_token", "Invalid, expired or revoked token")
+			}
+			if err != nil {
+				return err
 			}
-			return []byte(secret), nil
-		})
 
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired token",
-			})
+			c.SetUserContext(WithPrincipal(c.UserContext(), principal))
+			return c.Next()
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token claims",
-			})
+		claims, err := jwtService.ValidateToken(bearerToken[1])
+		if err != nil {
+			return unauthorized(c, "invalid_token", "Invalid or expired token")
 		}
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "User ID not found in token",
-			})
+		revoked, err := revocationChecker.IsTokenRevoked(c.UserContext(), claims.UserID, claims.ID, claims.IssuedAt.Time)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return unauthorized(c, "invalid_token", "Token has been revoked")
 		}
 
-		// Inject user_id ke context
-		ctx := context.WithValue(c.Context(), constant.UserIDKey, int64(userID))
-		c.SetUserContext(ctx)
+		c.SetUserContext(WithPrincipal(c.UserContext(), &model.Principal{
+			UserID:         claims.UserID,
+			Email:          claims.Email,
+			Roles:          claims.Roles,
+			TokenID:        claims.ID,
+			TokenExpiresAt: claims.ExpiresAt.Time,
+			AuthMethod:     model.AuthMethodJWT,
+			AMR:            claims.AMR,
+		}))
 
 		return c.Next()
 	}
 }
+
+// unauthorized answers with 401 and a WWW-Authenticate challenge as described in RFC 6750.
+// errorCode is empty when the request carried no credentials at all.
+func unauthorized(c *fiber.Ctx, errorCode, description string) error {
+	challenge := fmt.Sprintf(`Bearer realm=%q`, authRealm)
+	if errorCode != "" {
+		challenge += fmt.Sprintf(`, error=%q, error_description=%q`, errorCode, description)
+	}
+	c.Set(fiber.HeaderWWWAuthenticate, challenge)
+
+	appErr := apperror.Unauthorized(description)
+	if errorCode != "" {
+		appErr = appErr.WithCode(errorCode)
+	}
+	return appErr
+}
+
+// RequireScope rejects principals whose personal access token lacks scope.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			return unauthorized(c, "", "Authentication required")
+		}
+		if !principal.HasScope(scope) {
+			return insufficientScope(c, scope)
+		}
+		return c.Next()
+	}
+}
+
+// RequireMethodScope requires the read scope for safe methods and the write scope for everything else.
+func RequireMethodScope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scope := model.ScopeWrite
+		if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Method() == fiber.MethodOptions {
+			scope = model.ScopeRead
+		}
+		return RequireScope(scope)(c)
+	}
+}
+
+func insufficientScope(c *fiber.Ctx, scope string) error {
+	description := fmt.Sprintf("Token requires the %s scope", scope)
+	c.Set(fiber.HeaderWWWAuthenticate, fmt.Sprintf(`Bearer realm=%q, error="insufficient_scope", error_description=%q, scope=%q`,
+		authRealm, description, scope))
+
+	return apperror.Forbidden(description).WithCode("insufficient_scope")
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type fakeRevocationChecker map[string]bool
+
+func (f fakeRevocationChecker) IsTokenRevoked(_ context.Context, _ int64, tokenID string, _ time.Time) (bool, error) {
+	return f[tokenID], nil
+}
+
+type fakePATAuthenticator map[string]*model.Principal
+
+func (f fakePATAuthenticator) Authenticate(_ context.Context, token string) (*model.Principal, error) {
+	principal, ok := f[token]
+	if !ok {
+		return nil, utils.ErrInvalidToken
+	}
+	return principal, nil
+}
+
+func TestJWTAuth(t *testing.T) {
+	jwtService, err := service.NewJwtService(&config.JwtConfig{SecretKey: "jwt_key", ExpirationInSecond: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessToken, err := jwtService.GenerateToken(1, "jwt@example.com", []string{model.AMRMFA}, model.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revokedToken, err := jwtService.GenerateToken(1, "jwt@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revokedClaims, err := jwtService.ValidateToken(revokedToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patAuthenticator := fakePATAuthenticator{
+		"gmp_valid": {UserID: 2, Email: "pat@example.com", AuthMethod: model.AuthMethodPersonalAccessToken, Scopes: []string{model.ScopeRead}},
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantPrincipal string // email, auth method dan roles
+		wantChallenge string
+	}{
+		{name: "no credentials", wantStatus: fiber.StatusUnauthorized, wantChallenge: `Bearer realm="go-management-project"`},
+		{name: "not a bearer token", authorization: "Basic abc", wantStatus: fiber.StatusUnauthorized, wantChallenge: `error="invalid_request"`},
+		{name: "jwt", authorization: "Bearer " + accessToken, wantStatus: fiber.StatusOK, wantPrincipal: "jwt@example.com jwt admin"},
+		{name: "invalid jwt", authorization: "Bearer a.b.c", wantStatus: fiber.StatusUnauthorized, wantChallenge: `error="invalid_token"`},
+		{name: "revoked jwt", authorization: "Bearer " + revokedToken, wantStatus: fiber.StatusUnauthorized, wantChallenge: `error_description="Token has been revoked"`},
+		{name: "personal access token", authorization: "Bearer gmp_valid", wantStatus: fiber.StatusOK, wantPrincipal: "pat@example.com personal_access_token "},
+		{name: "unknown personal access token", authorization: "Bearer gmp_unknown", wantStatus: fiber.StatusUnauthorized, wantChallenge: `error_description="Invalid, expired or revoked token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", JWTAuth(jwtService, fakeRevocationChecker{revokedClaims.ID: true}, patAuthenticator), func(c *fiber.Ctx) error {
+				principal, _ := GetPrincipal(c)
+				return c.SendString(principal.Email + " " + principal.AuthMethod + " " + strings.Join(principal.Roles, ","))
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if challenge := resp.Header.Get(fiber.HeaderWWWAuthenticate); !strings.Contains(challenge, tt.wantChallenge) {
+				t.Errorf("WWW-Authenticate = %q, want %q", challenge, tt.wantChallenge)
+			}
+			if tt.wantPrincipal != "" {
+				body := make([]byte, 256)
+				n, _ := resp.Body.Read(body)
+				if got := string(body[:n]); got != tt.wantPrincipal {
+					t.Errorf("principal = %q, want %q", got, tt.wantPrincipal)
+				}
+			}
+		})
+	}
+}
+
+func TestRequireMethodScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		scopes     []string
+		wantStatus int
+	}{
+		{name: "read token reads", method: fiber.MethodGet, scopes: []string{model.ScopeRead}, wantStatus: fiber.StatusOK},
+		{name: "read token writes", method: fiber.MethodPost, scopes: []string{model.ScopeRead}, wantStatus: fiber.StatusForbidden},
+		{name: "write token writes", method: fiber.MethodPost, scopes: []string{model.ScopeWrite}, wantStatus: fiber.StatusOK},
+		{name: "interactive login is unrestricted", method: fiber.MethodPost, scopes: nil, wantStatus: fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Use(func(c *fiber.Ctx) error {
+				c.SetUserContext(WithPrincipal(c.UserContext(), &model.Principal{UserID: 1, Scopes: tt.scopes}))
+				return c.Next()
+			}, RequireMethodScope())
+			app.All("/", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"github.com/MCPutro/go-management-project/internal/config/constant"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WithPrincipal stores the principal in ctx. The user ID is also stored under constant.UserIDKey
+// for code that only needs the ID.
+func WithPrincipal(ctx context.Context, principal *model.Principal) context.Context {
+	ctx = context.WithValue(ctx, constant.PrincipalKey, principal)
+	return context.WithValue(ctx, constant.UserIDKey, principal.UserID)
+}
+
+func PrincipalFromContext(ctx context.Context) (*model.Principal, bool) {
+	principal, ok := ctx.Value(constant.PrincipalKey).(*model.Principal)
+	return principal, ok && principal != nil
+}
+
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(constant.UserIDKey).(int64)
+	return userID, ok
+}
+
+// GetPrincipal returns the principal set by JWTAuth for the current request.
+func GetPrincipal(c *fiber.Ctx) (*model.Principal, bool) {
+	return PrincipalFromContext(c.UserContext())
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, service.RateLimitRule) (service.RateLimitResult, error) {
+	return service.RateLimitResult{}, errors.New("store is down")
+}
+
+func TestRateLimiter_Handler(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRuleConfig{Limit: 100, PeriodInSecond: 60},
+		Groups:  map[string]config.RateLimitRuleConfig{"auth": {Limit: 2, PeriodInSecond: 60}},
+	}
+
+	tests := []struct {
+		name          string
+		config        *config.RateLimitConfig
+		store         service.RateLimitStore
+		requests      int
+		wantStatus    int
+		wantHeaders   map[string]string
+		wantNoHeaders []string
+	}{
+		{
+			name:       "within the limit",
+			config:     cfg,
+			store:      service.NewMemoryRateLimitStore(),
+			requests:   1,
+			wantStatus: fiber.StatusOK,
+			wantHeaders: map[string]string{
+				"RateLimit-Policy":    "2;w=60",
+				"RateLimit-Limit":     "2",
+				"RateLimit-Remaining": "1",
+				"RateLimit-Reset":     "30",
+			},
+			wantNoHeaders: []string{fiber.HeaderRetryAfter},
+		},
+		{
+			name:       "over the limit",
+			config:     cfg,
+			store:      service.NewMemoryRateLimitStore(),
+			requests:   3,
+			wantStatus: fiber.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"RateLimit-Limit":       "2",
+				"RateLimit-Remaining":   "0",
+				fiber.HeaderRetryAfter:  "30",
+				fiber.HeaderContentType: "application/problem+json",
+			},
+		},
+		{
+			name:          "disabled",
+			config:        &config.RateLimitConfig{Enabled: false},
+			store:         failingRateLimitStore{},
+			requests:      1,
+			wantStatus:    fiber.StatusOK,
+			wantNoHeaders: []string{"RateLimit-Limit"},
+		},
+		{
+			name:          "store failing open",
+			config:        &config.RateLimitConfig{Enabled: true, FailOpen: true},
+			store:         failingRateLimitStore{},
+			requests:      1,
+			wantStatus:    fiber.StatusOK,
+			wantNoHeaders: []string{"RateLimit-Limit"},
+		},
+		{
+			name:       "store failing closed",
+			config:     &config.RateLimitConfig{Enabled: true},
+			store:      failingRateLimitStore{},
+			requests:   1,
+			wantStatus: fiber.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", NewRateLimiter(tt.config, tt.store).Handler("Auth"), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			var status int
+			var header func(string) string
+			for i := 0; i < tt.requests; i++ {
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+				status, header = resp.StatusCode, resp.Header.Get
+			}
+
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := header(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+			for _, name := range tt.wantNoHeaders {
+				if got := header(name); got != "" {
+					t.Errorf("header %s = %q, want none", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_IPHandler(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRuleConfig{Limit: 100, PeriodInSecond: 60},
+		Groups:  map[string]config.RateLimitRuleConfig{"auth_ip": {Limit: 2, PeriodInSecond: 60}},
+	}
+
+	tests := []struct {
+		name       string
+		handler    func(r *RateLimiter) fiber.Handler
+		wantStatus int
+	}{
+		// setiap request memakai user berbeda dari IP yang sama
+		{name: "per ip", handler: func(r *RateLimiter) fiber.Handler { return r.IPHandler("auth_ip") }, wantStatus: fiber.StatusTooManyRequests},
+		{name: "per user", handler: func(r *RateLimiter) fiber.Handler { return r.Handler("auth_ip") }, wantStatus: fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userID int64
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", func(c *fiber.Ctx) error {
+				userID++
+				c.SetUserContext(WithPrincipal(c.UserContext(), &model.Principal{UserID: userID}))
+				return c.Next()
+			}, tt.handler(NewRateLimiter(cfg, service.NewMemoryRateLimitStore())), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			var status int
+			for i := 0; i < 3; i++ {
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+				status = resp.StatusCode
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status of the third request = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID reuses the X-Request-ID sent by the caller (e.g. a gateway) or generates one, echoes it
+// in the response and puts it in the request context so every log record of the request has it.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.SetUserContext(applog.WithRequestID(c.UserContext(), requestID))
+
+		return c.Next()
+	}
+}
+
+// validRequestID only accepts short IDs made of safe characters, so a client cannot inject
+// arbitrary content into logs.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/MCPutro/go-management-project/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span per request, continuing the trace of the caller when it sent a
+// W3C traceparent header. Like Metrics it belongs after AccessLog, which then logs with the span
+// in its context.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), requestHeaderCarrier{c})
+		ctx, span := tracing.Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.ClientAddress(c.IP()),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status, route := responseStatus(c, err)
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+
+		return err
+	}
+}
+
+// requestHeaderCarrier exposes the request headers to the propagator.
+type requestHeaderCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h requestHeaderCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h requestHeaderCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h requestHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(h.c.GetReqHeaders()))
+	for key := range h.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -3,9 +3,11 @@ package model
 import "time"
 
 type Audit struct {
-	CreatedAt time.Time  `json:"created_at"`
-	CreatedBy int64      `json:"created_by"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	UpdatedBy int64      `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy int64     `json:"created_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy int64     `json:"updated_by"`
+	// Version naik setiap kali baris diubah, dipakai untuk optimistic locking
+	Version   int64      `json:"version"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+const (
+	AuditActionAccountLocked = "account_locked"
+	AuditActionIPLocked      = "ip_locked"
+	// AuditActionConfigReloaded records runtime settings changed by editing the properties file.
+	AuditActionConfigReloaded = "config_reloaded"
+)
+
+type AuditLog struct {
+	ID        int64
+	UserID    *int64
+	Action    string
+	IPAddress string
+	Detail    string
+	CreatedAt time.Time
+}
@@ -8,3 +8,13 @@ type Card struct {
 	Position int    `json:"position"`
 	Audit           // 👈 EMBED AUDIT STRUCT
 }
+
+// CardPatch holds the fields changed by a partial update; nil fields are left as they are.
+type CardPatch struct {
+	Title     *string
+	Content   *string
+	Position  *int
+	UpdatedBy int64
+	// Version is the version the caller expects; 0 skips the check.
+	Version int64
+}
@@ -7,3 +7,12 @@ type List struct {
 	Position  int    `json:"position"`
 	Audit            // 👈 EMBED AUDIT STRUCT
 }
+
+// ListPatch holds the fields changed by a partial update; nil fields are left as they are.
+type ListPatch struct {
+	Name      *string
+	Position  *int
+	UpdatedBy int64
+	// Version is the version the caller expects; 0 skips the check.
+	Version int64
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// LoginAttempt counts failed logins for one key (an account or a client IP) within a window.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	FirstFailedAt time.Time
+	LockedUntil   *time.Time
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+var PersonalAccessTokenScopes = []string{ScopeRead, ScopeWrite}
+
+type PersonalAccessToken struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	TokenHash   string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	AuthMethodJWT                 = "jwt"
+	AuthMethodPersonalAccessToken = "personal_access_token"
+)
+
+// AMRMFA is the authentication method reference (RFC 8176) of a login verified with a second factor.
+const AMRMFA = "mfa"
+
+// Principal is the authenticated caller of a request, as established by the auth middleware.
+type Principal struct {
+	UserID         int64
+	Email          string
+	Roles          []string
+	TokenID        string
+	TokenExpiresAt time.Time
+	AuthMethod     string
+	// Scopes limits what a personal access token may do; nil means unrestricted (interactive login).
+	Scopes []string
+	// AMR lists how the session was authenticated, see AMRMFA.
+	AMR []string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) MFAVerified() bool {
+	for _, method := range p.AMR {
+		if method == AMRMFA {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the principal may use admin rights. The admin role only counts in a
+// session verified with a second factor, so a leaked password alone never grants it.
+func (p *Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin) && p.MFAVerified()
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	if p.Scopes == nil {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -6,3 +6,12 @@ type Project struct {
 	Description string `json:"description"`
 	Audit              // 👈 EMBED AUDIT STRUCT
 }
+
+// ProjectPatch holds the fields changed by a partial update; nil fields are left as they are.
+type ProjectPatch struct {
+	Name        *string
+	Description *string
+	UpdatedBy   int64
+	// Version is the version the caller expects; 0 skips the check.
+	Version int64
+}
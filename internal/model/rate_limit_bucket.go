@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// RateLimitBucket is the token bucket of one key (a route group and a user or client IP).
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+	// FullAt is when the bucket has refilled completely and can be dropped.
+	FullAt time.Time
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshToken struct {
+	ID         int64
+	UserID     int64
+	FamilyID   string
+	TokenHash  string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *int64
+	// MFAVerified is carried over on rotation, so a refreshed session keeps its second factor.
+	MFAVerified bool
+	CreatedAt   time.Time
+}
+
+type RevokedToken struct {
+	JTI       string
+	UserID    int64
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
@@ -1,9 +1,26 @@
 package model
 
+import "time"
+
+const RoleAdmin = "admin"
+
 type User struct {
-	ID       int64  `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"-"` // jangan dikirim ke frontend
-	Audit           // 👈 EMBED AUDIT STRUCT
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	Password string   `json:"-"` // jangan dikirim ke frontend
+	Roles    []string `json:"roles"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	Audit // 👈 EMBED AUDIT STRUCT
+}
+
+// UserPatch holds the fields changed by a partial update; nil fields are left as they are.
+type UserPatch struct {
+	Name      *string
+	Email     *string
+	UpdatedBy int64
+	// Version is the version the caller expects; 0 skips the check.
+	Version int64
 }
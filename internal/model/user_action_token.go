@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	ActionTokenPurposeEmailVerification = "email_verification"
+	ActionTokenPurposePasswordReset     = "password_reset"
+	// ActionTokenPurposeMFALogin tokens bridge the password and TOTP steps of a login.
+	ActionTokenPurposeMFALogin = "mfa_login"
+)
+
+// UserActionToken records a signed single-use token (email verification, password reset) so it
+// can be consumed exactly once.
+type UserActionToken struct {
+	ID        string
+	UserID    int64
+	Purpose   string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
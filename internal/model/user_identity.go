@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// UserIdentity links a User to an account at an external identity provider.
+type UserIdentity struct {
+	ID          int64
+	UserID      int64
+	Provider    string
+	Subject     string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
+
+// ExternalIdentity is what the identity provider asserted about the user in a verified ID token.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
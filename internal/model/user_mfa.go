@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// UserMFA is the TOTP enrollment of a user. It only protects logins once EnabledAt is set, which
+// happens after the user proves the authenticator app works.
+type UserMFA struct {
+	UserID int64
+	Secret string
+	// LastUsedStep is the TOTP time step of the last accepted code, so a code cannot be replayed.
+	LastUsedStep int64
+	EnabledAt    *time.Time
+	CreatedAt    time.Time
+}
+
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAChallenge is returned by login instead of a token pair when the account has 2FA enabled.
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
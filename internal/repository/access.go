@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+// Projects, lists and cards are only visible to the owner of their project. The access conditions
+// below are added to every query of those tables; %d is the placeholder of the caller's user ID.
+// Rows the caller may not access are reported as utils.ErrNotFound, the same as missing rows, so
+// their IDs cannot be probed.
+const (
+	projectAccess = `projects.created_by = $%d`
+	listAccess    = `EXISTS (SELECT 1 FROM projects WHERE projects.id = lists.project_id AND projects.deleted_at IS NULL AND projects.created_by = $%d)`
+	cardAccess    = `EXISTS (SELECT 1 FROM lists JOIN projects ON projects.id = lists.project_id
+		WHERE lists.id = cards.list_id AND lists.deleted_at IS NULL AND projects.deleted_at IS NULL AND projects.created_by = $%d)`
+)
+
+// requireAccess returns utils.ErrNotFound unless the live row id of table exists and userID may
+// access it, e.g. before a list is created in a project.
+func requireAccess(ctx context.Context, tx *sql.Tx, table, access string, id, userID int64) error {
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NULL AND "+access+")", table, 2)
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, id, userID).Scan(&exists); err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if !exists {
+		return utils.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, entry *model.AuditLog) error
+}
+
+type auditLogRepository struct {
+}
+
+func NewAuditLogRepository() AuditLogRepository {
+	return &auditLogRepository{}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, tx *sql.Tx, entry *model.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (user_id, action, ip_address, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id
+	`
+	entry.CreatedAt = time.Now()
+
+	err := tx.QueryRowContext(ctx, query,
+		entry.UserID, entry.Action, entry.IPAddress, entry.Detail, entry.CreatedAt,
+	).Scan(&entry.ID)
+	return applog.WrapError(ctx, err)
+}
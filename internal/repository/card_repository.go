@@ -4,18 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/MCPutro/go-management-project/utils"
 	"time"
 
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
 	"github.com/MCPutro/go-management-project/internal/model"
 )
 
+// CardRepository only reads and writes the cards of projects owned by the given user: userID for
+// reads, CreatedBy, UpdatedBy or deletedBy for writes. Other cards and lists are utils.ErrNotFound;
+// GetByListID returns no cards and no error for an accessible list without cards.
 type CardRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, card *model.Card) error
-	GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.Card, error)
-	GetByListID(ctx context.Context, tx *sql.Tx, listID int64) ([]*model.Card, error)
+	GetByID(ctx context.Context, tx *sql.Tx, id, userID int64) (*model.Card, error)
+	GetByListID(ctx context.Context, tx *sql.Tx, listID, userID int64) ([]*model.Card, error)
 	Update(ctx context.Context, tx *sql.Tx, card *model.Card) error
-	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error
+	Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.CardPatch) (*model.Card, error)
+	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error
 }
 
 type cardRepository struct {
@@ -26,28 +32,37 @@ func NewCardRepository() CardRepository {
 }
 
 func (r *cardRepository) Create(ctx context.Context, tx *sql.Tx, card *model.Card) error {
+	// card hanya bisa dibuat di list dari project milik pembuatnya
+	err := requireAccess(ctx, tx, "lists", listAccess, card.ListID, card.CreatedBy)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+
 	query := `
 		INSERT INTO cards (list_id, title, content, position, created_at, created_by, updated_at, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, version
 	`
 	now := time.Now()
-	return tx.QueryRowContext(ctx, query,
+	card.CreatedAt = now
+	card.UpdatedAt = now
+	err = tx.QueryRowContext(ctx, query,
 		card.ListID, card.Title, card.Content, card.Position,
 		now, card.CreatedBy,
 		now, card.UpdatedBy,
-	).Scan(&card.ID)
+	).Scan(&card.ID, &card.Version)
+	return applog.WrapError(ctx, err)
 }
 
-func (r *cardRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.Card, error) {
-	query := `SELECT id, list_id, title, content, position, created_at, created_by, updated_at, updated_by, deleted_at FROM cards WHERE id = $1 AND deleted_at IS NULL`
-	row := tx.QueryRowContext(ctx, query, id)
+func (r *cardRepository) GetByID(ctx context.Context, tx *sql.Tx, id, userID int64) (*model.Card, error) {
+	query := fmt.Sprintf(`SELECT id, list_id, title, content, position, created_at, created_by, updated_at, updated_by, version, deleted_at FROM cards WHERE id = $1 AND deleted_at IS NULL AND `+cardAccess, 2)
+	row := tx.QueryRowContext(ctx, query, id, userID)
 
 	var card model.Card
 	var deletedAt sql.NullTime
 
 	err := row.Scan(
 		&card.ID, &card.ListID, &card.Title, &card.Content, &card.Position,
-		&card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.UpdatedBy,
+		&card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.UpdatedBy, &card.Version,
 		&deletedAt,
 	)
 
@@ -56,7 +71,7 @@ func (r *cardRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*mo
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 
 	if deletedAt.Valid {
@@ -66,11 +81,11 @@ func (r *cardRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*mo
 	return &card, nil
 }
 
-func (r *cardRepository) GetByListID(ctx context.Context, tx *sql.Tx, listID int64) ([]*model.Card, error) {
-	query := `SELECT id, list_id, title, content, position, created_at, created_by, updated_at, updated_by, deleted_at FROM cards WHERE list_id = $1 AND deleted_at IS NULL ORDER BY position ASC`
-	rows, err := tx.QueryContext(ctx, query, listID)
+func (r *cardRepository) GetByListID(ctx context.Context, tx *sql.Tx, listID, userID int64) ([]*model.Card, error) {
+	query := fmt.Sprintf(`SELECT id, list_id, title, content, position, created_at, created_by, updated_at, updated_by, version, deleted_at FROM cards WHERE list_id = $1 AND deleted_at IS NULL AND `+cardAccess+` ORDER BY position ASC`, 2)
+	rows, err := tx.QueryContext(ctx, query, listID, userID)
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -81,11 +96,11 @@ func (r *cardRepository) GetByListID(ctx context.Context, tx *sql.Tx, listID int
 
 		err := rows.Scan(
 			&card.ID, &card.ListID, &card.Title, &card.Content, &card.Position,
-			&card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.UpdatedBy,
+			&card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.UpdatedBy, &card.Version,
 			&deletedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, applog.WrapError(ctx, err)
 		}
 		if deletedAt.Valid {
 			card.DeletedAt = &deletedAt.Time
@@ -93,28 +108,79 @@ func (r *cardRepository) GetByListID(ctx context.Context, tx *sql.Tx, listID int
 		cards = append(cards, &card)
 	}
 
+	// kosong bisa berarti list memang belum punya isi atau bukan milik user
 	if len(cards) == 0 {
-		return nil, utils.ErrNotFound
+		return nil, requireAccess(ctx, tx, "lists", listAccess, listID, userID)
 	}
 
 	return cards, nil
 }
 
 func (r *cardRepository) Update(ctx context.Context, tx *sql.Tx, card *model.Card) error {
-	query := `
-		UPDATE cards SET title = $1, content = $2, position = $3, updated_at = $4, updated_by = $5
-		WHERE id = $6 AND deleted_at IS NULL
-	`
+	query := fmt.Sprintf(`
+		UPDATE cards SET title = $1, content = $2, position = $3, updated_at = $4, updated_by = $5, version = version + 1
+		WHERE id = $6 AND deleted_at IS NULL AND (version = $7 OR $7 = 0) AND `+cardAccess+`
+		RETURNING list_id, created_at, created_by, updated_at, version
+	`, 5)
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query,
-		card.Title, card.Content, card.Position, now, card.UpdatedBy, card.ID,
+	err := tx.QueryRowContext(ctx, query,
+		card.Title, card.Content, card.Position, now, card.UpdatedBy, card.ID, card.Version,
+	).Scan(&card.ListID, &card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingAccessibleRowError(ctx, tx, "cards", cardAccess, card.ID, card.UpdatedBy)
+	}
+	return applog.WrapError(ctx, err)
+}
+
+// Patch updates only the columns present in patch and returns the row as stored.
+func (r *cardRepository) Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.CardPatch) (*model.Card, error) {
+	var set setClause
+	if patch.Title != nil {
+		set.add("title", *patch.Title)
+	}
+	if patch.Content != nil {
+		set.add("content", *patch.Content)
+	}
+	if patch.Position != nil {
+		set.add("position", *patch.Position)
+	}
+	set.add("updated_at", time.Now())
+	set.add("updated_by", patch.UpdatedBy)
+	set.restrict(cardAccess, patch.UpdatedBy)
+
+	query, args := set.query("cards", id, patch.Version, "id, list_id, title, content, position, created_at, created_by, updated_at, updated_by, version")
+
+	var card model.Card
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&card.ID, &card.ListID, &card.Title, &card.Content, &card.Position,
+		&card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.UpdatedBy, &card.Version,
 	)
-	return err
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingAccessibleRowError(ctx, tx, "cards", cardAccess, id, patch.UpdatedBy)
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return &card, nil
 }
 
-func (r *cardRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error {
-	query := `UPDATE cards SET deleted_at = $1, updated_at = $2, updated_by = $3 WHERE id = $4 AND deleted_at IS NULL`
+func (r *cardRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error {
+	query := fmt.Sprintf(`
+		UPDATE cards SET deleted_at = $1, updated_at = $2, updated_by = $3, version = version + 1
+		WHERE id = $4 AND deleted_at IS NULL AND (version = $5 OR $5 = 0) AND `+cardAccess+`
+	`, 3)
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query, now, now, deletedBy, id)
-	return err
+	result, err := tx.ExecContext(ctx, query, now, now, deletedBy, id, version)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if affected == 0 {
+		return missingAccessibleRowError(ctx, tx, "cards", cardAccess, id, deletedBy)
+	}
+	return nil
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/lib/pq"
+)
+
+// pqUniqueViolation is the SQLSTATE Postgres reports when a UNIQUE constraint is violated.
+const pqUniqueViolation = "23505"
+
+// mapWriteError turns a unique violation into a conflict error with the given message and adds the
+// request ID of ctx to other errors.
+func mapWriteError(ctx context.Context, err error, conflictMessage string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		return apperror.Conflict(conflictMessage).Wrap(err)
+	}
+	return applog.WrapError(ctx, err)
+}
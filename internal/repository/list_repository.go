@@ -4,18 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/MCPutro/go-management-project/utils"
 	"time"
 
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
 	"github.com/MCPutro/go-management-project/internal/model"
 )
 
+// ListRepository only reads and writes the lists of projects owned by the given user: userID for
+// reads, CreatedBy, UpdatedBy or deletedBy for writes. Other lists and projects are utils.ErrNotFound;
+// GetByProjectID returns no lists and no error for an accessible project without lists.
 type ListRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, list *model.List) error
-	GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.List, error)
-	GetByProjectID(ctx context.Context, tx *sql.Tx, projectID int64) ([]*model.List, error)
+	GetByID(ctx context.Context, tx *sql.Tx, id, userID int64) (*model.List, error)
+	GetByProjectID(ctx context.Context, tx *sql.Tx, projectID, userID int64) ([]*model.List, error)
 	Update(ctx context.Context, tx *sql.Tx, list *model.List) error
-	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error
+	Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.ListPatch) (*model.List, error)
+	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error
 }
 
 type listRepository struct {
@@ -26,28 +32,37 @@ func NewListRepository() ListRepository {
 }
 
 func (r *listRepository) Create(ctx context.Context, tx *sql.Tx, list *model.List) error {
+	// list hanya bisa dibuat di project milik pembuatnya
+	err := requireAccess(ctx, tx, "projects", projectAccess, list.ProjectID, list.CreatedBy)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+
 	query := `
 		INSERT INTO lists (project_id, name, position, created_at, created_by, updated_at, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, version
 	`
 	now := time.Now()
-	return tx.QueryRowContext(ctx, query,
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	err = tx.QueryRowContext(ctx, query,
 		list.ProjectID, list.Name, list.Position,
 		now, list.CreatedBy,
 		now, list.UpdatedBy,
-	).Scan(&list.ID)
+	).Scan(&list.ID, &list.Version)
+	return applog.WrapError(ctx, err)
 }
 
-func (r *listRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.List, error) {
-	query := `SELECT id, project_id, name, position, created_at, created_by, updated_at, updated_by, deleted_at FROM lists WHERE id = $1 AND deleted_at IS NULL`
-	row := tx.QueryRowContext(ctx, query, id)
+func (r *listRepository) GetByID(ctx context.Context, tx *sql.Tx, id, userID int64) (*model.List, error) {
+	query := fmt.Sprintf(`SELECT id, project_id, name, position, created_at, created_by, updated_at, updated_by, version, deleted_at FROM lists WHERE id = $1 AND deleted_at IS NULL AND `+listAccess, 2)
+	row := tx.QueryRowContext(ctx, query, id, userID)
 
 	var list model.List
 	var deletedAt sql.NullTime
 
 	err := row.Scan(
 		&list.ID, &list.ProjectID, &list.Name, &list.Position,
-		&list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.UpdatedBy,
+		&list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.UpdatedBy, &list.Version,
 		&deletedAt,
 	)
 
@@ -56,7 +71,7 @@ func (r *listRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*mo
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 
 	if deletedAt.Valid {
@@ -66,11 +81,11 @@ func (r *listRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*mo
 	return &list, nil
 }
 
-func (r *listRepository) GetByProjectID(ctx context.Context, tx *sql.Tx, projectID int64) ([]*model.List, error) {
-	query := `SELECT id, project_id, name, position, created_at, created_by, updated_at, updated_by, deleted_at FROM lists WHERE project_id = $1 AND deleted_at IS NULL ORDER BY position ASC`
-	rows, err := tx.QueryContext(ctx, query, projectID)
+func (r *listRepository) GetByProjectID(ctx context.Context, tx *sql.Tx, projectID, userID int64) ([]*model.List, error) {
+	query := fmt.Sprintf(`SELECT id, project_id, name, position, created_at, created_by, updated_at, updated_by, version, deleted_at FROM lists WHERE project_id = $1 AND deleted_at IS NULL AND `+listAccess+` ORDER BY position ASC`, 2)
+	rows, err := tx.QueryContext(ctx, query, projectID, userID)
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -81,11 +96,11 @@ func (r *listRepository) GetByProjectID(ctx context.Context, tx *sql.Tx, project
 
 		err := rows.Scan(
 			&list.ID, &list.ProjectID, &list.Name, &list.Position,
-			&list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.UpdatedBy,
+			&list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.UpdatedBy, &list.Version,
 			&deletedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, applog.WrapError(ctx, err)
 		}
 		if deletedAt.Valid {
 			list.DeletedAt = &deletedAt.Time
@@ -93,28 +108,76 @@ func (r *listRepository) GetByProjectID(ctx context.Context, tx *sql.Tx, project
 		lists = append(lists, &list)
 	}
 
+	// kosong bisa berarti project memang belum punya isi atau bukan milik user
 	if len(lists) == 0 {
-		return nil, utils.ErrNotFound
+		return nil, requireAccess(ctx, tx, "projects", projectAccess, projectID, userID)
 	}
 
 	return lists, nil
 }
 
 func (r *listRepository) Update(ctx context.Context, tx *sql.Tx, list *model.List) error {
-	query := `
-		UPDATE lists SET name = $1, position = $2, updated_at = $3, updated_by = $4
-		WHERE id = $5 AND deleted_at IS NULL
-	`
+	query := fmt.Sprintf(`
+		UPDATE lists SET name = $1, position = $2, updated_at = $3, updated_by = $4, version = version + 1
+		WHERE id = $5 AND deleted_at IS NULL AND (version = $6 OR $6 = 0) AND `+listAccess+`
+		RETURNING project_id, created_at, created_by, updated_at, version
+	`, 4)
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query,
-		list.Name, list.Position, now, list.UpdatedBy, list.ID,
+	err := tx.QueryRowContext(ctx, query,
+		list.Name, list.Position, now, list.UpdatedBy, list.ID, list.Version,
+	).Scan(&list.ProjectID, &list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingAccessibleRowError(ctx, tx, "lists", listAccess, list.ID, list.UpdatedBy)
+	}
+	return applog.WrapError(ctx, err)
+}
+
+// Patch updates only the columns present in patch and returns the row as stored.
+func (r *listRepository) Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.ListPatch) (*model.List, error) {
+	var set setClause
+	if patch.Name != nil {
+		set.add("name", *patch.Name)
+	}
+	if patch.Position != nil {
+		set.add("position", *patch.Position)
+	}
+	set.add("updated_at", time.Now())
+	set.add("updated_by", patch.UpdatedBy)
+	set.restrict(listAccess, patch.UpdatedBy)
+
+	query, args := set.query("lists", id, patch.Version, "id, project_id, name, position, created_at, created_by, updated_at, updated_by, version")
+
+	var list model.List
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&list.ID, &list.ProjectID, &list.Name, &list.Position,
+		&list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.UpdatedBy, &list.Version,
 	)
-	return err
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingAccessibleRowError(ctx, tx, "lists", listAccess, id, patch.UpdatedBy)
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return &list, nil
 }
 
-func (r *listRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error {
-	query := `UPDATE lists SET deleted_at = $1, updated_at = $2, updated_by = $3 WHERE id = $4 AND deleted_at IS NULL`
+func (r *listRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error {
+	query := fmt.Sprintf(`
+		UPDATE lists SET deleted_at = $1, updated_at = $2, updated_by = $3, version = version + 1
+		WHERE id = $4 AND deleted_at IS NULL AND (version = $5 OR $5 = 0) AND `+listAccess+`
+	`, 3)
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query, now, now, deletedBy, id)
-	return err
+	result, err := tx.ExecContext(ctx, query, now, now, deletedBy, id, version)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if affected == 0 {
+		return missingAccessibleRowError(ctx, tx, "lists", listAccess, id, deletedBy)
+	}
+	return nil
 }
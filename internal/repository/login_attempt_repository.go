@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MCPutro/go-management-project/utils"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, tx *sql.Tx, key string) (*model.LoginAttempt, error)
+	// Increment counts an attempt in one statement; a counter whose first failure is older than
+	// windowStart restarts at one and a counter locked at now is left as it is.
+	Increment(ctx context.Context, tx *sql.Tx, key string, now, windowStart time.Time) (*model.LoginAttempt, error)
+	Decrement(ctx context.Context, tx *sql.Tx, key string) error
+	Lock(ctx context.Context, tx *sql.Tx, key string, until time.Time) error
+	Delete(ctx context.Context, tx *sql.Tx, key string) error
+	// DeleteStale removes counters whose first failure and lockout both lie before the given time.
+	DeleteStale(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error)
+}
+
+type loginAttemptRepository struct {
+}
+
+func NewLoginAttemptRepository() LoginAttemptRepository {
+	return &loginAttemptRepository{}
+}
+
+func (r *loginAttemptRepository) Get(ctx context.Context, tx *sql.Tx, key string) (*model.LoginAttempt, error) {
+	query := `SELECT key, failures, first_failed_at, locked_until FROM login_attempts WHERE key = $1`
+	row := tx.QueryRowContext(ctx, query, key)
+
+	attempt, err := scanLoginAttempt(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return attempt, nil
+}
+
+func (r *loginAttemptRepository) Increment(ctx context.Context, tx *sql.Tx, key string, now, windowStart time.Time) (*model.LoginAttempt, error) {
+	query := `
+		INSERT INTO login_attempts (key, failures, first_failed_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.locked_until > $2 THEN login_attempts.failures
+				WHEN login_attempts.first_failed_at < $3 THEN 1
+				ELSE login_attempts.failures + 1 END,
+			first_failed_at = CASE
+				WHEN login_attempts.locked_until > $2 THEN login_attempts.first_failed_at
+				WHEN login_attempts.first_failed_at < $3 THEN $2
+				ELSE login_attempts.first_failed_at END
+		RETURNING key, failures, first_failed_at, locked_until
+	`
+	row := tx.QueryRowContext(ctx, query, key, now, windowStart)
+
+	attempt, err := scanLoginAttempt(row)
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return attempt, nil
+}
+
+func (r *loginAttemptRepository) Decrement(ctx context.Context, tx *sql.Tx, key string) error {
+	query := `UPDATE login_attempts SET failures = GREATEST(failures - 1, 0) WHERE key = $1`
+	_, err := tx.ExecContext(ctx, query, key)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *loginAttemptRepository) Lock(ctx context.Context, tx *sql.Tx, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = $1 WHERE key = $2`
+	_, err := tx.ExecContext(ctx, query, until, key)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *loginAttemptRepository) Delete(ctx context.Context, tx *sql.Tx, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+	_, err := tx.ExecContext(ctx, query, key)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *loginAttemptRepository) DeleteStale(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	query := `DELETE FROM login_attempts WHERE first_failed_at < $1 AND (locked_until IS NULL OR locked_until < $1)`
+	result, err := tx.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	return affected, applog.WrapError(ctx, err)
+}
+
+func scanLoginAttempt(row rowScanner) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	var lockedUntil sql.NullTime
+
+	err := row.Scan(&attempt.Key, &attempt.Failures, &attempt.FirstFailedAt, &lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		attempt.LockedUntil = &lockedUntil.Time
+	}
+
+	return &attempt, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// setClause collects the assignments of a column-selective UPDATE. Column names always come
+// from the repository itself, only values are passed as placeholders.
+type setClause struct {
+	assignments []string
+	args        []interface{}
+	access      string
+	userID      int64
+}
+
+func (s *setClause) add(column string, value interface{}) {
+	s.args = append(s.args, value)
+	s.assignments = append(s.assignments, fmt.Sprintf("%s = $%d", column, len(s.args)))
+}
+
+// restrict only updates the row when userID may access it, see projectAccess.
+func (s *setClause) restrict(access string, userID int64) {
+	s.access = access
+	s.userID = userID
+}
+
+// query builds the UPDATE for the live row with the given id. The row version is bumped, and when
+// version is not 0 the row is only updated if it still has that version.
+func (s *setClause) query(table string, id, version int64, returning string) (string, []interface{}) {
+	args := append(s.args, id, version)
+	where := fmt.Sprintf("id = $%d AND deleted_at IS NULL AND (version = $%d OR $%d = 0)", len(args)-1, len(args), len(args))
+	if s.access != "" {
+		args = append(args, s.userID)
+		where += " AND " + fmt.Sprintf(s.access, len(args))
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s, version = version + 1 WHERE %s RETURNING %s",
+		table, strings.Join(s.assignments, ", "), where, returning)
+	return query, args
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/lib/pq"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, token *model.PersonalAccessToken) error
+	GetByHash(ctx context.Context, tx *sql.Tx, tokenHash string) (*model.PersonalAccessToken, error)
+	GetByUserID(ctx context.Context, tx *sql.Tx, userID int64) ([]*model.PersonalAccessToken, error)
+	Revoke(ctx context.Context, tx *sql.Tx, id, userID int64) error
+	UpdateLastUsed(ctx context.Context, tx *sql.Tx, id int64, lastUsedAt time.Time) error
+	RevokeAllByUserID(ctx context.Context, tx *sql.Tx, userID int64) error
+}
+
+type personalAccessTokenRepository struct {
+}
+
+func NewPersonalAccessTokenRepository() PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{}
+}
+
+func (r *personalAccessTokenRepository) Create(ctx context.Context, tx *sql.Tx, token *model.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+	`
+	token.CreatedAt = time.Now()
+
+	err := tx.QueryRowContext(ctx, query,
+		token.UserID, token.Name, token.TokenPrefix, token.TokenHash,
+		pq.Array(token.Scopes), token.ExpiresAt, token.CreatedAt,
+	).Scan(&token.ID)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *personalAccessTokenRepository) GetByHash(ctx context.Context, tx *sql.Tx, tokenHash string) (*model.PersonalAccessToken, error) {
+	query := `SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM personal_access_tokens WHERE token_hash = $1`
+	row := tx.QueryRowContext(ctx, query, tokenHash)
+
+	token, err := scanPersonalAccessToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return token, nil
+}
+
+func (r *personalAccessTokenRepository) GetByUserID(ctx context.Context, tx *sql.Tx, userID int64) ([]*model.PersonalAccessToken, error) {
+	query := `SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM personal_access_tokens WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+	defer rows.Close()
+
+	tokens := []*model.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, applog.WrapError(ctx, err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, applog.WrapError(ctx, rows.Err())
+}
+
+func (r *personalAccessTokenRepository) Revoke(ctx context.Context, tx *sql.Tx, id, userID int64) error {
+	query := `UPDATE personal_access_tokens SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, time.Now(), id, userID)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if affected == 0 {
+		return utils.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *personalAccessTokenRepository) RevokeAllByUserID(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `UPDATE personal_access_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, time.Now(), userID)
+	return applog.WrapError(ctx, err)
+}
+
+// UpdateLastUsed never moves last_used_at backwards, so a late batch cannot overwrite a newer value.
+func (r *personalAccessTokenRepository) UpdateLastUsed(ctx context.Context, tx *sql.Tx, id int64, lastUsedAt time.Time) error {
+	query := `UPDATE personal_access_tokens SET last_used_at = $1 WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $1)`
+	_, err := tx.ExecContext(ctx, query, lastUsedAt, id)
+	return applog.WrapError(ctx, err)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPersonalAccessToken(row rowScanner) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.TokenHash,
+		pq.Array(&token.Scopes), &expiresAt, &lastUsedAt, &revokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
@@ -4,19 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/MCPutro/go-management-project/utils"
 
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
 	"time"
 
 	"github.com/MCPutro/go-management-project/internal/model"
 )
 
+// ProjectRepository only reads and writes the projects of the given user: userID for reads and
+// UpdatedBy or deletedBy for writes. Other projects are utils.ErrNotFound.
 type ProjectRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, project *model.Project) error
-	GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.Project, error)
+	GetByID(ctx context.Context, tx *sql.Tx, id, userID int64) (*model.Project, error)
 	Update(ctx context.Context, tx *sql.Tx, project *model.Project) error
-	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error
-	GetAll(ctx context.Context, tx *sql.Tx) ([]*model.Project, error)
+	Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.ProjectPatch) (*model.Project, error)
+	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error
+	GetAll(ctx context.Context, tx *sql.Tx, userID int64) ([]*model.Project, error)
 }
 
 type projectRepository struct {
@@ -29,26 +34,29 @@ func NewProjectRepository() ProjectRepository {
 func (r *projectRepository) Create(ctx context.Context, tx *sql.Tx, project *model.Project) error {
 	query := `
 		INSERT INTO projects (name, description, created_at, created_by, updated_at, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, version
 	`
 	now := time.Now()
-	return tx.QueryRowContext(ctx, query,
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	err := tx.QueryRowContext(ctx, query,
 		project.Name, project.Description,
 		now, project.CreatedBy,
 		now, project.UpdatedBy,
-	).Scan(&project.ID)
+	).Scan(&project.ID, &project.Version)
+	return applog.WrapError(ctx, err)
 }
 
-func (r *projectRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.Project, error) {
-	query := `SELECT id, name, description, created_at, created_by, updated_at, updated_by, deleted_at FROM projects WHERE id = $1 AND deleted_at IS NULL`
-	row := tx.QueryRowContext(ctx, query, id)
+func (r *projectRepository) GetByID(ctx context.Context, tx *sql.Tx, id, userID int64) (*model.Project, error) {
+	query := fmt.Sprintf(`SELECT id, name, description, created_at, created_by, updated_at, updated_by, version, deleted_at FROM projects WHERE id = $1 AND deleted_at IS NULL AND `+projectAccess, 2)
+	row := tx.QueryRowContext(ctx, query, id, userID)
 
 	var project model.Project
 	var deletedAt sql.NullTime
 
 	err := row.Scan(
 		&project.ID, &project.Name, &project.Description,
-		&project.CreatedAt, &project.CreatedBy, &project.UpdatedAt, &project.UpdatedBy,
+		&project.CreatedAt, &project.CreatedBy, &project.UpdatedAt, &project.UpdatedBy, &project.Version,
 		&deletedAt,
 	)
 
@@ -57,7 +65,7 @@ func (r *projectRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 
 	if deletedAt.Valid {
@@ -68,29 +76,76 @@ func (r *projectRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (
 }
 
 func (r *projectRepository) Update(ctx context.Context, tx *sql.Tx, project *model.Project) error {
-	query := `
-		UPDATE projects SET name = $1, description = $2, updated_at = $3, updated_by = $4
-		WHERE id = $5 AND deleted_at IS NULL
-	`
+	query := fmt.Sprintf(`
+		UPDATE projects SET name = $1, description = $2, updated_at = $3, updated_by = $4, version = version + 1
+		WHERE id = $5 AND deleted_at IS NULL AND (version = $6 OR $6 = 0) AND `+projectAccess+`
+		RETURNING created_at, created_by, updated_at, version
+	`, 4)
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query,
-		project.Name, project.Description, now, project.UpdatedBy, project.ID,
+	err := tx.QueryRowContext(ctx, query,
+		project.Name, project.Description, now, project.UpdatedBy, project.ID, project.Version,
+	).Scan(&project.CreatedAt, &project.CreatedBy, &project.UpdatedAt, &project.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingAccessibleRowError(ctx, tx, "projects", projectAccess, project.ID, project.UpdatedBy)
+	}
+	return applog.WrapError(ctx, err)
+}
+
+// Patch updates only the columns present in patch and returns the row as stored.
+func (r *projectRepository) Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.ProjectPatch) (*model.Project, error) {
+	var set setClause
+	if patch.Name != nil {
+		set.add("name", *patch.Name)
+	}
+	if patch.Description != nil {
+		set.add("description", *patch.Description)
+	}
+	set.add("updated_at", time.Now())
+	set.add("updated_by", patch.UpdatedBy)
+	set.restrict(projectAccess, patch.UpdatedBy)
+
+	query, args := set.query("projects", id, patch.Version, "id, name, description, created_at, created_by, updated_at, updated_by, version")
+
+	var project model.Project
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&project.ID, &project.Name, &project.Description,
+		&project.CreatedAt, &project.CreatedBy, &project.UpdatedAt, &project.UpdatedBy, &project.Version,
 	)
-	return err
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingAccessibleRowError(ctx, tx, "projects", projectAccess, id, patch.UpdatedBy)
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return &project, nil
 }
 
-func (r *projectRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error {
-	query := `UPDATE projects SET deleted_at = $1, updated_at = $2, updated_by = $3 WHERE id = $4 AND deleted_at IS NULL`
+func (r *projectRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error {
+	query := fmt.Sprintf(`
+		UPDATE projects SET deleted_at = $1, updated_at = $2, updated_by = $3, version = version + 1
+		WHERE id = $4 AND deleted_at IS NULL AND (version = $5 OR $5 = 0) AND `+projectAccess+`
+	`, 3)
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query, now, now, deletedBy, id)
-	return err
+	result, err := tx.ExecContext(ctx, query, now, now, deletedBy, id, version)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if affected == 0 {
+		return missingAccessibleRowError(ctx, tx, "projects", projectAccess, id, deletedBy)
+	}
+	return nil
 }
 
-func (r *projectRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]*model.Project, error) {
-	query := `SELECT id, name, description, created_at, created_by, updated_at, updated_by, deleted_at FROM projects WHERE deleted_at IS NULL`
-	rows, err := tx.QueryContext(ctx, query)
+func (r *projectRepository) GetAll(ctx context.Context, tx *sql.Tx, userID int64) ([]*model.Project, error) {
+	query := fmt.Sprintf(`SELECT id, name, description, created_at, created_by, updated_at, updated_by, version, deleted_at FROM projects WHERE deleted_at IS NULL AND `+projectAccess, 1)
+	rows, err := tx.QueryContext(ctx, query, userID)
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -101,11 +156,11 @@ func (r *projectRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]*model.Pr
 
 		err := rows.Scan(
 			&project.ID, &project.Name, &project.Description,
-			&project.CreatedAt, &project.CreatedBy, &project.UpdatedAt, &project.UpdatedBy,
+			&project.CreatedAt, &project.CreatedBy, &project.UpdatedAt, &project.UpdatedBy, &project.Version,
 			&deletedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, applog.WrapError(ctx, err)
 		}
 		if deletedAt.Valid {
 			project.DeletedAt = &deletedAt.Time
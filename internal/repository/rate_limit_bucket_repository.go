@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type RateLimitBucketRepository interface {
+	// GetForUpdate locks the bucket of key until the transaction ends, creating a bucket holding
+	// tokens first when the key has none.
+	GetForUpdate(ctx context.Context, tx *sql.Tx, key string, tokens float64, now time.Time) (*model.RateLimitBucket, error)
+	Update(ctx context.Context, tx *sql.Tx, bucket *model.RateLimitBucket) error
+	// DeleteFull removes buckets that refilled completely before the given time.
+	DeleteFull(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error)
+}
+
+type rateLimitBucketRepository struct {
+}
+
+func NewRateLimitBucketRepository() RateLimitBucketRepository {
+	return &rateLimitBucketRepository{}
+}
+
+func (r *rateLimitBucketRepository) GetForUpdate(ctx context.Context, tx *sql.Tx, key string, tokens float64, now time.Time) (*model.RateLimitBucket, error) {
+	insert := `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at) VALUES ($1, $2, $3, $3)
+		ON CONFLICT (key) DO NOTHING
+	`
+	_, err := tx.ExecContext(ctx, insert, key, tokens, now)
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	query := `SELECT key, tokens, updated_at, full_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`
+	var bucket model.RateLimitBucket
+	err = tx.QueryRowContext(ctx, query, key).Scan(&bucket.Key, &bucket.Tokens, &bucket.UpdatedAt, &bucket.FullAt)
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return &bucket, nil
+}
+
+func (r *rateLimitBucketRepository) Update(ctx context.Context, tx *sql.Tx, bucket *model.RateLimitBucket) error {
+	query := `UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2, full_at = $3 WHERE key = $4`
+	_, err := tx.ExecContext(ctx, query, bucket.Tokens, bucket.UpdatedAt, bucket.FullAt, bucket.Key)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *rateLimitBucketRepository) DeleteFull(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	query := `DELETE FROM rate_limit_buckets WHERE full_at < $1`
+	result, err := tx.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
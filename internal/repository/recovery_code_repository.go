@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/utils"
+	"time"
+)
+
+type RecoveryCodeRepository interface {
+	// Replace drops every recovery code of the user and stores the given hashes instead.
+	Replace(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error
+	// Consume marks an unused code as used and returns ErrNotFound otherwise.
+	Consume(ctx context.Context, tx *sql.Tx, userID int64, codeHash string) error
+	DeleteByUserID(ctx context.Context, tx *sql.Tx, userID int64) error
+}
+
+type recoveryCodeRepository struct {
+}
+
+func NewRecoveryCodeRepository() RecoveryCodeRepository {
+	return &recoveryCodeRepository{}
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	err := r.DeleteByUserID(ctx, tx, userID)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+
+	query := `INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3)`
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		_, err = tx.ExecContext(ctx, query, userID, codeHash, now)
+		if err != nil {
+			return applog.WrapError(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepository) Consume(ctx context.Context, tx *sql.Tx, userID int64, codeHash string) error {
+	query := `UPDATE user_recovery_codes SET used_at = $1 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL RETURNING id`
+
+	var id int64
+	err := tx.QueryRowContext(ctx, query, time.Now(), userID, codeHash).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrNotFound
+	}
+	return applog.WrapError(ctx, err)
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM user_recovery_codes WHERE user_id = $1`
+	_, err := tx.ExecContext(ctx, query, userID)
+	return applog.WrapError(ctx, err)
+}
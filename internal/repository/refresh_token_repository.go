@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MCPutro/go-management-project/utils"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, token *model.RefreshToken) error
+	GetByHashForUpdate(ctx context.Context, tx *sql.Tx, tokenHash string) (*model.RefreshToken, error)
+	Revoke(ctx context.Context, tx *sql.Tx, id int64, replacedBy *int64) error
+	RevokeFamily(ctx context.Context, tx *sql.Tx, familyID string) error
+	RevokeAllByUserID(ctx context.Context, tx *sql.Tx, userID int64) error
+}
+
+type refreshTokenRepository struct {
+}
+
+func NewRefreshTokenRepository() RefreshTokenRepository {
+	return &refreshTokenRepository{}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, tx *sql.Tx, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa_verified, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+	`
+	token.CreatedAt = time.Now()
+
+	err := tx.QueryRowContext(ctx, query,
+		token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.MFAVerified, token.CreatedAt,
+	).Scan(&token.ID)
+	return applog.WrapError(ctx, err)
+}
+
+// GetByHashForUpdate locks the row so concurrent refreshes of the same token are serialized.
+func (r *refreshTokenRepository) GetByHashForUpdate(ctx context.Context, tx *sql.Tx, tokenHash string) (*model.RefreshToken, error) {
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, mfa_verified, created_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`
+	row := tx.QueryRowContext(ctx, query, tokenHash)
+
+	var token model.RefreshToken
+	var revokedAt sql.NullTime
+	var replacedBy sql.NullInt64
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &revokedAt, &replacedBy, &token.MFAVerified, &token.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if replacedBy.Valid {
+		token.ReplacedBy = &replacedBy.Int64
+	}
+
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, tx *sql.Tx, id int64, replacedBy *int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1, replaced_by = $2 WHERE id = $3 AND revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, time.Now(), replacedBy, id)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, tx *sql.Tx, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, time.Now(), familyID)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *refreshTokenRepository) RevokeAllByUserID(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, time.Now(), userID)
+	return applog.WrapError(ctx, err)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type RevokedTokenRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, token *model.RevokedToken) error
+	// IsRevoked reports whether the token was revoked on its own or was issued before the
+	// tokens of its user were invalidated (users.tokens_invalidated_at).
+	IsRevoked(ctx context.Context, tx *sql.Tx, jti string, userID int64, issuedAt time.Time) (bool, error)
+	DeleteExpired(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error)
+}
+
+type revokedTokenRepository struct {
+}
+
+func NewRevokedTokenRepository() RevokedTokenRepository {
+	return &revokedTokenRepository{}
+}
+
+func (r *revokedTokenRepository) Create(ctx context.Context, tx *sql.Tx, token *model.RevokedToken) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (jti) DO NOTHING
+	`
+	token.RevokedAt = time.Now()
+
+	_, err := tx.ExecContext(ctx, query, token.JTI, token.UserID, token.ExpiresAt, token.RevokedAt)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, tx *sql.Tx, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR EXISTS(SELECT 1 FROM users WHERE id = $2 AND tokens_invalidated_at > $3)
+	`
+
+	var revoked bool
+	err := tx.QueryRowContext(ctx, query, jti, userID, issuedAt).Scan(&revoked)
+	return revoked, applog.WrapError(ctx, err)
+}
+
+// DeleteExpired removes denylist entries whose tokens would be rejected by their exp claim anyway.
+func (r *revokedTokenRepository) DeleteExpired(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	query := `DELETE FROM revoked_tokens WHERE expires_at < $1`
+	result, err := tx.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	return affected, applog.WrapError(ctx, err)
+}
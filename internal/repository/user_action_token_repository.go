@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MCPutro/go-management-project/utils"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type UserActionTokenRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, token *model.UserActionToken) error
+	// LockUnused locks an unused, unexpired token so concurrent uses of it are serialized, and
+	// returns ErrNotFound otherwise.
+	LockUnused(ctx context.Context, tx *sql.Tx, id string, userID int64, purpose string) error
+	// Consume marks an unused, unexpired token as used and returns ErrNotFound otherwise.
+	Consume(ctx context.Context, tx *sql.Tx, id string, userID int64, purpose string) error
+	// InvalidateByUserID marks every outstanding token of a purpose as used, so only the newest link works.
+	InvalidateByUserID(ctx context.Context, tx *sql.Tx, userID int64, purpose string) error
+	// RecordFailure counts a wrong code entered with the token and marks the token as used once
+	// maxAttempts is reached.
+	RecordFailure(ctx context.Context, tx *sql.Tx, id string, maxAttempts int) error
+}
+
+type userActionTokenRepository struct {
+}
+
+func NewUserActionTokenRepository() UserActionTokenRepository {
+	return &userActionTokenRepository{}
+}
+
+func (r *userActionTokenRepository) Create(ctx context.Context, tx *sql.Tx, token *model.UserActionToken) error {
+	query := `
+		INSERT INTO user_action_tokens (id, user_id, purpose, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	token.CreatedAt = time.Now()
+
+	_, err := tx.ExecContext(ctx, query, token.ID, token.UserID, token.Purpose, token.ExpiresAt, token.CreatedAt)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userActionTokenRepository) LockUnused(ctx context.Context, tx *sql.Tx, id string, userID int64, purpose string) error {
+	query := `
+		SELECT id FROM user_action_tokens
+		WHERE id = $1 AND user_id = $2 AND purpose = $3 AND used_at IS NULL AND expires_at > $4
+		FOR UPDATE
+	`
+	var locked string
+	err := tx.QueryRowContext(ctx, query, id, userID, purpose, time.Now()).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrNotFound
+	}
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userActionTokenRepository) Consume(ctx context.Context, tx *sql.Tx, id string, userID int64, purpose string) error {
+	query := `
+		UPDATE user_action_tokens SET used_at = $1
+		WHERE id = $2 AND user_id = $3 AND purpose = $4 AND used_at IS NULL AND expires_at > $1
+		RETURNING id
+	`
+	var consumed string
+	err := tx.QueryRowContext(ctx, query, time.Now(), id, userID, purpose).Scan(&consumed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrNotFound
+	}
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userActionTokenRepository) InvalidateByUserID(ctx context.Context, tx *sql.Tx, userID int64, purpose string) error {
+	query := `UPDATE user_action_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, time.Now(), userID, purpose)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userActionTokenRepository) RecordFailure(ctx context.Context, tx *sql.Tx, id string, maxAttempts int) error {
+	query := `
+		UPDATE user_action_tokens
+		SET failed_attempts = failed_attempts + 1,
+			used_at = CASE WHEN failed_attempts + 1 >= $2 THEN $3 ELSE used_at END
+		WHERE id = $1 AND used_at IS NULL
+	`
+	_, err := tx.ExecContext(ctx, query, id, maxAttempts, time.Now())
+	return applog.WrapError(ctx, err)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MCPutro/go-management-project/utils"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, identity *model.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, tx *sql.Tx, provider, subject string) (*model.UserIdentity, error)
+	UpdateLastLogin(ctx context.Context, tx *sql.Tx, id int64, email string) error
+}
+
+type userIdentityRepository struct {
+}
+
+func NewUserIdentityRepository() UserIdentityRepository {
+	return &userIdentityRepository{}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, tx *sql.Tx, identity *model.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+	`
+	now := time.Now()
+	identity.CreatedAt = now
+	identity.LastLoginAt = now
+
+	err := tx.QueryRowContext(ctx, query,
+		identity.UserID, identity.Provider, identity.Subject, identity.Email, now, now,
+	).Scan(&identity.ID)
+	return mapWriteError(ctx, err, "Identity is already linked to an account")
+}
+
+func (r *userIdentityRepository) GetByProviderSubject(ctx context.Context, tx *sql.Tx, provider, subject string) (*model.UserIdentity, error) {
+	query := `SELECT id, user_id, provider, subject, email, created_at, last_login_at FROM user_identities WHERE provider = $1 AND subject = $2`
+	row := tx.QueryRowContext(ctx, query, provider, subject)
+
+	var identity model.UserIdentity
+	err := row.Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email,
+		&identity.CreatedAt, &identity.LastLoginAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) UpdateLastLogin(ctx context.Context, tx *sql.Tx, id int64, email string) error {
+	query := `UPDATE user_identities SET email = $1, last_login_at = $2 WHERE id = $3`
+	_, err := tx.ExecContext(ctx, query, email, time.Now(), id)
+	return applog.WrapError(ctx, err)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MCPutro/go-management-project/utils"
+	"time"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+type UserMFARepository interface {
+	// Save stores a pending enrollment, replacing an earlier one that was never confirmed.
+	Save(ctx context.Context, tx *sql.Tx, mfa *model.UserMFA) error
+	GetByUserIDForUpdate(ctx context.Context, tx *sql.Tx, userID int64) (*model.UserMFA, error)
+	Enable(ctx context.Context, tx *sql.Tx, userID int64) error
+	UpdateLastUsedStep(ctx context.Context, tx *sql.Tx, userID, step int64) error
+	Delete(ctx context.Context, tx *sql.Tx, userID int64) error
+}
+
+type userMFARepository struct {
+}
+
+func NewUserMFARepository() UserMFARepository {
+	return &userMFARepository{}
+}
+
+func (r *userMFARepository) Save(ctx context.Context, tx *sql.Tx, mfa *model.UserMFA) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret, last_used_step, created_at)
+		VALUES ($1, $2, 0, $3)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = EXCLUDED.created_at
+		WHERE user_mfa.enabled_at IS NULL
+	`
+	mfa.CreatedAt = time.Now()
+
+	result, err := tx.ExecContext(ctx, query, mfa.UserID, mfa.Secret, mfa.CreatedAt)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if affected == 0 {
+		return utils.ErrInvalidInput
+	}
+	return nil
+}
+
+func (r *userMFARepository) GetByUserIDForUpdate(ctx context.Context, tx *sql.Tx, userID int64) (*model.UserMFA, error) {
+	query := `SELECT user_id, secret, last_used_step, enabled_at, created_at FROM user_mfa WHERE user_id = $1 FOR UPDATE`
+	row := tx.QueryRowContext(ctx, query, userID)
+
+	var mfa model.UserMFA
+	var enabledAt sql.NullTime
+
+	err := row.Scan(&mfa.UserID, &mfa.Secret, &mfa.LastUsedStep, &enabledAt, &mfa.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrNotFound
+	}
+	if err != nil {
+		return nil, applog.WrapError(ctx, err)
+	}
+	if enabledAt.Valid {
+		mfa.EnabledAt = &enabledAt.Time
+	}
+
+	return &mfa, nil
+}
+
+func (r *userMFARepository) Enable(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `UPDATE user_mfa SET enabled_at = $1 WHERE user_id = $2`
+	_, err := tx.ExecContext(ctx, query, time.Now(), userID)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userMFARepository) UpdateLastUsedStep(ctx context.Context, tx *sql.Tx, userID, step int64) error {
+	query := `UPDATE user_mfa SET last_used_step = $1 WHERE user_id = $2`
+	_, err := tx.ExecContext(ctx, query, step, userID)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userMFARepository) Delete(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM user_mfa WHERE user_id = $1`
+	_, err := tx.ExecContext(ctx, query, userID)
+	return applog.WrapError(ctx, err)
+}
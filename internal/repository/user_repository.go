@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/MCPutro/go-management-project/utils"
 	"time"
 
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
 	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
@@ -15,8 +18,13 @@ type UserRepository interface {
 	GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.User, error)
 	GetByEmail(ctx context.Context, tx *sql.Tx, email string) (*model.User, error)
 	Update(ctx context.Context, tx *sql.Tx, user *model.User) error
-	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error
+	Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.UserPatch) (*model.User, error)
+	Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error
 	GetAll(ctx context.Context, tx *sql.Tx) ([]*model.User, error)
+	MarkEmailVerified(ctx context.Context, tx *sql.Tx, id int64) error
+	UpdatePassword(ctx context.Context, tx *sql.Tx, id int64, password string) error
+	// InvalidateTokens rejects every access token of the user issued before at.
+	InvalidateTokens(ctx context.Context, tx *sql.Tx, id int64, at time.Time) error
 }
 
 type userRepository struct {
@@ -29,37 +37,41 @@ func NewUserRepository() UserRepository {
 func (r *userRepository) Create(ctx context.Context, tx *sql.Tx, user *model.User) error {
 	query := `
 		INSERT INTO users (name, email, password, created_at, created_by, updated_at, updated_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, roles, version
 	`
 	
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
 
-	return tx.QueryRowContext(ctx, query,
+	err := tx.QueryRowContext(ctx, query,
 		user.Name, user.Email, user.Password,
 		now, user.CreatedBy,
 		now, user.UpdatedBy,
-	).Scan(&user.ID)
+	).Scan(&user.ID, pq.Array(&user.Roles), &user.Version)
+	return mapWriteError(ctx, err, "Email is already registered")
 }
 
 func (r *userRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*model.User, error) {
-	query := `SELECT id, name, email, created_at, created_by, updated_at, updated_by, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL`
+	query := `SELECT id, name, email, roles, email_verified_at, created_at, created_by, updated_at, updated_by, version, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL`
 	row := tx.QueryRowContext(ctx, query, id)
 
 	var user model.User
-	var deletedAt sql.NullTime
+	var emailVerifiedAt, deletedAt sql.NullTime
 
 	err := row.Scan(
-		&user.ID, &user.Name, &user.Email,
-		&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy,
+		&user.ID, &user.Name, &user.Email, pq.Array(&user.Roles), &emailVerifiedAt,
+		&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy, &user.Version,
 		&deletedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, utils.ErrNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
 	}
 	if deletedAt.Valid {
 		user.DeletedAt = &deletedAt.Time
@@ -69,22 +81,25 @@ func (r *userRepository) GetByID(ctx context.Context, tx *sql.Tx, id int64) (*mo
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, tx *sql.Tx, email string) (*model.User, error) {
-	query := `SELECT id, name, email, password, created_at, created_by, updated_at, updated_by, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL`
+	query := `SELECT id, name, email, password, roles, email_verified_at, created_at, created_by, updated_at, updated_by, version, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL`
 	row := tx.QueryRowContext(ctx, query, email)
 
 	var user model.User
-	var deletedAt sql.NullTime
+	var emailVerifiedAt, deletedAt sql.NullTime
 
 	err := row.Scan(
-		&user.ID, &user.Name, &user.Email, &user.Password,
-		&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy,
+		&user.ID, &user.Name, &user.Email, &user.Password, pq.Array(&user.Roles), &emailVerifiedAt,
+		&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy, &user.Version,
 		&deletedAt,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, utils.ErrNotFound
 	}
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
 	}
 	if deletedAt.Valid {
 		user.DeletedAt = &deletedAt.Time
@@ -95,43 +110,106 @@ func (r *userRepository) GetByEmail(ctx context.Context, tx *sql.Tx, email strin
 
 func (r *userRepository) Update(ctx context.Context, tx *sql.Tx, user *model.User) error {
 	query := `
-		UPDATE users SET name = $1, email = $2, updated_at = $3, updated_by = $4
-		WHERE id = $5 AND deleted_at IS NULL
+		UPDATE users SET name = $1, email = $2, updated_at = $3, updated_by = $4, version = version + 1,
+			email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+		WHERE id = $5 AND deleted_at IS NULL AND (version = $6 OR $6 = 0)
+		RETURNING roles, email_verified_at, created_at, created_by, updated_at, version
 	`
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query,
-		user.Name, user.Email, now, user.UpdatedBy, user.ID,
+	var emailVerifiedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, query,
+		user.Name, user.Email, now, user.UpdatedBy, user.ID, user.Version,
+	).Scan(pq.Array(&user.Roles), &emailVerifiedAt, &user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingRowError(ctx, tx, "users", user.ID)
+	}
+	if err != nil {
+		return mapWriteError(ctx, err, "Email is already registered")
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	return nil
+}
+
+// Patch updates only the columns present in patch and returns the row as stored.
+func (r *userRepository) Patch(ctx context.Context, tx *sql.Tx, id int64, patch *model.UserPatch) (*model.User, error) {
+	var set setClause
+	if patch.Name != nil {
+		set.add("name", *patch.Name)
+	}
+	if patch.Email != nil {
+		set.add("email", *patch.Email)
+		// email baru harus diverifikasi ulang; CASE dievaluasi dengan nilai email yang lama
+		set.assignments = append(set.assignments, fmt.Sprintf("email_verified_at = CASE WHEN email = $%d THEN email_verified_at END", len(set.args)))
+	}
+	set.add("updated_at", time.Now())
+	set.add("updated_by", patch.UpdatedBy)
+
+	query, args := set.query("users", id, patch.Version, "id, name, email, roles, email_verified_at, created_at, created_by, updated_at, updated_by, version")
+
+	var user model.User
+	var emailVerifiedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&user.ID, &user.Name, &user.Email, pq.Array(&user.Roles), &emailVerifiedAt,
+		&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy, &user.Version,
 	)
-	return err
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missingRowError(ctx, tx, "users", id)
+	}
+	if err != nil {
+		return nil, mapWriteError(ctx, err, "Email is already registered")
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+
+	return &user, nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy int64) error {
-	query := `UPDATE users SET deleted_at = $1, updated_at = $2, updated_by = $3 WHERE id = $4 AND deleted_at IS NULL`
+func (r *userRepository) Delete(ctx context.Context, tx *sql.Tx, id, deletedBy, version int64) error {
+	query := `
+		UPDATE users SET deleted_at = $1, updated_at = $2, updated_by = $3, version = version + 1
+		WHERE id = $4 AND deleted_at IS NULL AND (version = $5 OR $5 = 0)
+	`
 	now := time.Now()
-	_, err := tx.ExecContext(ctx, query, now, now, deletedBy, id)
-	return err
+	result, err := tx.ExecContext(ctx, query, now, now, deletedBy, id, version)
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if affected == 0 {
+		return missingRowError(ctx, tx, "users", id)
+	}
+	return nil
 }
 
 func (r *userRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]*model.User, error) {
-	query := `SELECT id, name, email, created_at, created_by, updated_at, updated_by, deleted_at FROM users WHERE deleted_at IS NULL`
+	query := `SELECT id, name, email, roles, email_verified_at, created_at, created_by, updated_at, updated_by, version, deleted_at FROM users WHERE deleted_at IS NULL`
 	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, applog.WrapError(ctx, err)
 	}
 	defer rows.Close()
 
 	var users []*model.User
 	for rows.Next() {
 		var user model.User
-		var deletedAt sql.NullTime
+		var emailVerifiedAt, deletedAt sql.NullTime
 
 		err := rows.Scan(
-			&user.ID, &user.Name, &user.Email,
-			&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy,
+			&user.ID, &user.Name, &user.Email, pq.Array(&user.Roles), &emailVerifiedAt,
+			&user.CreatedAt, &user.CreatedBy, &user.UpdatedAt, &user.UpdatedBy, &user.Version,
 			&deletedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, applog.WrapError(ctx, err)
+		}
+		if emailVerifiedAt.Valid {
+			user.EmailVerifiedAt = &emailVerifiedAt.Time
 		}
 		if deletedAt.Valid {
 			user.DeletedAt = &deletedAt.Time
@@ -145,3 +223,22 @@ func (r *userRepository) GetAll(ctx context.Context, tx *sql.Tx) ([]*model.User,
 
 	return users, nil
 }
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, tx *sql.Tx, id int64) error {
+	query := `UPDATE users SET email_verified_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL AND email_verified_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, time.Now(), id)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, tx *sql.Tx, id int64, password string) error {
+	query := `UPDATE users SET password = $1, updated_at = $2, updated_by = $3, version = version + 1 WHERE id = $4 AND deleted_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, password, time.Now(), id, id)
+	return applog.WrapError(ctx, err)
+}
+
+func (r *userRepository) InvalidateTokens(ctx context.Context, tx *sql.Tx, id int64, at time.Time) error {
+	// iat di JWT hanya sampai detik, jadi batasnya juga dibulatkan ke detik
+	query := `UPDATE users SET tokens_invalidated_at = $1 WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, at.Truncate(time.Second), id)
+	return applog.WrapError(ctx, err)
+}
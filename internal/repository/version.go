@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	applog "github.com/MCPutro/go-management-project/internal/config/log"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+// missingRowError explains why a version-conditioned write matched no row: either the row does
+// not exist (anymore), or another request changed it after the caller read it.
+func missingRowError(ctx context.Context, tx *sql.Tx, table string, id int64) error {
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NULL)", table)
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	if !exists {
+		return utils.ErrNotFound
+	}
+	return utils.ErrVersionConflict
+}
+
+// missingAccessibleRowError is missingRowError for a table behind an access condition: a row the
+// user may not access is not found, whatever its version.
+func missingAccessibleRowError(ctx context.Context, tx *sql.Tx, table, access string, id, userID int64) error {
+	if err := requireAccess(ctx, tx, table, access, id, userID); err != nil {
+		return applog.WrapError(ctx, err)
+	}
+	return utils.ErrVersionConflict
+}
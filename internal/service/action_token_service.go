@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const actionTokenIssuer = "go-management-project/account"
+
+// ActionTokenService signs the tokens mailed for email verification and password reset. The
+// signature and expiry are checked here; single use is enforced by the stored token ID.
+type ActionTokenService interface {
+	Generate(purpose string, userID int64, tokenID string, expiresAt time.Time) (string, error)
+	Validate(token, purpose string) (*ActionTokenClaims, error)
+}
+
+type ActionTokenClaims struct {
+	UserID  int64  `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+type actionTokenService struct {
+	secret []byte
+}
+
+func NewActionTokenService(secret string) (ActionTokenService, error) {
+	if len(secret) < 16 {
+		return nil, errors.New("action token: secret must be at least 16 characters")
+	}
+	return &actionTokenService{secret: []byte(secret)}, nil
+}
+
+func (s *actionTokenService) Generate(purpose string, userID int64, tokenID string, expiresAt time.Time) (string, error) {
+	claims := ActionTokenClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    actionTokenIssuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *actionTokenService) Validate(token, purpose string) (*ActionTokenClaims, error) {
+	claims := &ActionTokenClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(actionTokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidToken, err)
+	}
+	// token verifikasi email tidak boleh dipakai untuk reset password, dan sebaliknya
+	if claims.Purpose != purpose || claims.ID == "" || claims.UserID == 0 {
+		return nil, fmt.Errorf("%w: token is not valid for %s", utils.ErrInvalidToken, purpose)
+	}
+
+	return claims, nil
+}
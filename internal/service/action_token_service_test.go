@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+func Test_actionTokenService_Validate(t *testing.T) {
+	s, err := NewActionTokenService("a-long-enough-account-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := NewActionTokenService("another-account-token-secret")
+
+	generate := func(s ActionTokenService, purpose string, expiresAt time.Time) string {
+		token, err := s.Generate(purpose, 7, "token-id", expiresAt)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose string
+		wantErr bool
+	}{
+		{
+			name:    "valid token",
+			token:   generate(s, model.ActionTokenPurposePasswordReset, time.Now().Add(time.Hour)),
+			purpose: model.ActionTokenPurposePasswordReset,
+			wantErr: false,
+		},
+		{
+			name:    "wrong purpose",
+			token:   generate(s, model.ActionTokenPurposeEmailVerification, time.Now().Add(time.Hour)),
+			purpose: model.ActionTokenPurposePasswordReset,
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   generate(s, model.ActionTokenPurposePasswordReset, time.Now().Add(-time.Minute)),
+			purpose: model.ActionTokenPurposePasswordReset,
+			wantErr: true,
+		},
+		{
+			name:    "signed with another secret",
+			token:   generate(other, model.ActionTokenPurposePasswordReset, time.Now().Add(time.Hour)),
+			purpose: model.ActionTokenPurposePasswordReset,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Validate(tt.token, tt.purpose)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if !errors.Is(err, utils.ErrInvalidToken) {
+					t.Errorf("Validate() error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if got.UserID != 7 || got.ID != "token-id" || got.Purpose != tt.purpose {
+				t.Errorf("Validate() got = %+v", got)
+			}
+		})
+	}
+}
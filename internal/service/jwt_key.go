@@ -0,0 +1,276 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	createdAt time.Time
+	retireAt  *time.Time // nil = tidak pernah pensiun
+}
+
+func (k *jwtKey) retired(now time.Time) bool {
+	return k.retireAt != nil && now.After(*k.retireAt)
+}
+
+// JSONWebKey is the public part of a signing key as published in the JWKS document (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// toJWK returns false for symmetric keys, which must never be published.
+func (k *jwtKey) toJWK() (JSONWebKey, bool) {
+	switch publicKey := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			Kty: "OKP",
+			Kid: k.id,
+			Use: "sig",
+			Alg: k.method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(publicKey),
+		}, true
+	default:
+		return JSONWebKey{}, false
+	}
+}
+
+func parseJwtKey(keyConfig config.JwtKeyConfig) (*jwtKey, error) {
+	if keyConfig.ID == "" {
+		return nil, errors.New("key id is required")
+	}
+
+	privatePEM, err := readKeyMaterial(keyConfig.PrivateKey, keyConfig.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	publicPEM, err := readKeyMaterial(keyConfig.PublicKey, keyConfig.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &jwtKey{id: keyConfig.ID}
+
+	switch keyConfig.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if keyConfig.Secret == "" {
+			return nil, errors.New("secret is required for HS256")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(keyConfig.Secret)
+		key.verifyKey = []byte(keyConfig.Secret)
+
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = privateKey
+			key.verifyKey = &privateKey.PublicKey
+		}
+		if publicPEM != nil {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = publicKey
+		}
+
+	case jwt.SigningMethodEdDSA.Alg():
+		key.method = jwt.SigningMethodEdDSA
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = privateKey
+			key.verifyKey = privateKey.(crypto.Signer).Public()
+		}
+		if publicPEM != nil {
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = publicKey
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", keyConfig.Algorithm)
+	}
+
+	if key.verifyKey == nil {
+		return nil, errors.New("private or public key is required")
+	}
+
+	return key, nil
+}
+
+func readKeyMaterial(inline, file string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if file == "" {
+		return nil, nil
+	}
+	return os.ReadFile(file)
+}
+
+// loadKeyDirectory reads every <kid>.pem private key in dir. Keys are sorted oldest first
+// (by file modification time); each one retires overlap after its successor was created.
+func loadKeyDirectory(dir string, overlap time.Duration) ([]*jwtKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*jwtKey
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parsePrivateKeyPEM(strings.TrimSuffix(filepath.Base(file), ".pem"), content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		key.createdAt = info.ModTime()
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].createdAt.Before(keys[b].createdAt)
+	})
+	for i := 0; i < len(keys)-1; i++ {
+		retireAt := keys[i+1].createdAt.Add(overlap)
+		keys[i].retireAt = &retireAt
+	}
+
+	return keys, nil
+}
+
+func parsePrivateKeyPEM(id string, content []byte) (*jwtKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+
+	var privateKey interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return &jwtKey{id: id, method: jwt.SigningMethodRS256, signKey: privateKey, verifyKey: &privateKey.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &jwtKey{id: id, method: jwt.SigningMethodEdDSA, signKey: privateKey, verifyKey: privateKey.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// writeKeyFile writes <kid>.pem through a temporary file, so another instance reloading the
+// directory never reads a partly written key.
+func writeKeyFile(dir, id string, content []byte) error {
+	file, err := os.CreateTemp(dir, "."+id+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(dir, id+".pem"))
+}
+
+// generateJwtKey creates a new key pair and returns it together with its PKCS#8 PEM encoding.
+// The caller stamps createdAt.
+func generateJwtKey(algorithm string) (*jwtKey, []byte, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("unsupported rotation algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	content := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	key, err := parsePrivateKeyPEM(uuid.NewString(), content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, content, nil
+}
@@ -1,46 +1,179 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/MCPutro/go-management-project/internal/config"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 )
 
 type JWTService interface {
-	GenerateToken(userID int64, email string) (string, error)
-	ValidateToken(token string) (int64, error)
+	// GenerateToken issues an access token; amr lists the authentication methods of the login (RFC 8176).
+	GenerateToken(userID int64, email string, amr []string, roles ...string) (string, error)
+	ValidateToken(token string) (*Claims, error)
+	// JWKS returns the public keys other services can use to verify our tokens.
+	JWKS() JSONWebKeySet
+	RotateKeys() error
+	// RunKeyRotation rotates the signing key on the configured schedule until ctx is done.
+	RunKeyRotation(ctx context.Context)
+	// RunKeyReload reloads the key directory on the configured interval until ctx is done, so keys
+	// rotated by another instance are published and used here as well.
+	RunKeyReload(ctx context.Context)
 }
+
 type jwtService struct {
-	secretKey []byte
-	expiresIn time.Time
+	mu         sync.RWMutex
+	signingKey *jwtKey
+	keys       map[string]*jwtKey
+
+	expiresIn    time.Duration
+	issuer       string
+	audience     []string
+	keyDirectory string
+	reloadEvery  time.Duration
+	// pinned is set when SigningKeyID chooses the signing key, a reload then never replaces it
+	pinned     bool
+	lastReload time.Time
+	rotation   config.JwtRotationConfig
+	overlap    time.Duration
+	// now is the clock for issuing and validating tokens, replaced in tests
+	now func() time.Time
 }
 
-func NewJwtService(config *config.JwtConfig) JWTService {
-	return &jwtService{
-		secretKey: []byte(config.SecretKey),
-		expiresIn: time.Now().Add(time.Duration(config.ExpirationInSecond) * time.Second),
+func NewJwtService(config *config.JwtConfig) (JWTService, error) {
+	j := &jwtService{
+		keys:         make(map[string]*jwtKey),
+		expiresIn:    time.Duration(config.ExpirationInSecond) * time.Second,
+		issuer:       config.Issuer,
+		audience:     config.Audience,
+		keyDirectory: config.KeyDirectory,
+		reloadEvery:  time.Duration(config.KeyReloadIntervalInSecond) * time.Second,
+		pinned:       config.SigningKeyID != "",
+		rotation:     config.Rotation,
+		overlap:      time.Duration(config.Rotation.OverlapInSecond) * time.Second,
+		now:          time.Now,
+	}
+	// key lama harus tetap bisa verifikasi sampai token terakhir yang ditandatanganinya expired
+	if j.overlap < j.expiresIn {
+		j.overlap = j.expiresIn
+	}
+
+	// tanpa Keys, pakai SecretKey (HS256) seperti sebelumnya
+	if len(config.Keys) == 0 && config.KeyDirectory == "" && !config.Rotation.Enabled {
+		key := &jwtKey{
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(config.SecretKey),
+			verifyKey: []byte(config.SecretKey),
+			createdAt: j.now(),
+		}
+		j.keys[key.id] = key
+		j.signingKey = key
+		return j, nil
+	}
+
+	for _, keyConfig := range config.Keys {
+		key, err := parseJwtKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", keyConfig.ID, err)
+		}
+		// key dari config tidak punya mtime, anggap dibuat saat dimuat supaya jadwal rotasi dan overlap dihitung dari sekarang
+		key.createdAt = j.now()
+		if err := j.addKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.KeyDirectory != "" {
+		keys, err := loadKeyDirectory(config.KeyDirectory, j.overlap)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key directory: %w", err)
+		}
+		for _, key := range keys {
+			if err := j.addKey(key); err != nil {
+				return nil, err
+			}
+		}
+		if len(keys) > 0 {
+			j.signingKey = keys[len(keys)-1]
+		}
+		j.lastReload = time.Now()
 	}
+
+	if config.SigningKeyID != "" {
+		signingKey, ok := j.keys[config.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("jwt signing key %q not found in keys", config.SigningKeyID)
+		}
+		j.signingKey = signingKey
+	}
+
+	if j.signingKey == nil && config.Rotation.Enabled {
+		if err := j.RotateKeys(); err != nil {
+			return nil, err
+		}
+	}
+	if j.signingKey == nil {
+		return nil, errors.New("jwt signing key is not configured")
+	}
+	if j.signingKey.signKey == nil {
+		return nil, fmt.Errorf("jwt signing key %q has no private key", j.signingKey.id)
+	}
+
+	j.pruneKeys(j.now())
+
+	return j, nil
 }
 
-type claims struct {
-	UserID int64 `json:"user_id"`
+func (j *jwtService) addKey(key *jwtKey) error {
+	if _, exists := j.keys[key.id]; exists {
+		return fmt.Errorf("jwt key %q: duplicate key id", key.id)
+	}
+	j.keys[key.id] = key
+	return nil
+}
+
+type Claims struct {
+	UserID int64    `json:"user_id"`
+	Email  string   `json:"email,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	AMR    []string `json:"amr,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func (j *jwtService) GenerateToken(userID int64, email string) (string, error) {
-	claim := &claims{
+func (j *jwtService) GenerateToken(userID int64, email string, amr []string, roles ...string) (string, error) {
+	j.mu.RLock()
+	signingKey := j.signingKey
+	j.mu.RUnlock()
+
+	now := j.now()
+	claim := &Claims{
 		UserID: userID,
+		Email:  email,
+		Roles:  roles,
+		AMR:    amr,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(j.expiresIn),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			Issuer:    j.issuer,
+			Audience:  j.audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	token := jwt.NewWithClaims(signingKey.method, claim)
+	if signingKey.id != "" {
+		token.Header["kid"] = signingKey.id
+	}
 
-	tokenString, err := token.SignedString(j.secretKey)
+	tokenString, err := token.SignedString(signingKey.signKey)
 	if err != nil {
 		return "", err
 	}
@@ -48,24 +181,258 @@ func (j *jwtService) GenerateToken(userID int64, email string) (string, error) {
 	return tokenString, nil
 }
 
-func (j *jwtService) ValidateToken(tokenString string) (int64, error) {
-	claims := &claims{}
+func (j *jwtService) ValidateToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, j.keyfunc, j.parserOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.ID == "" {
+		return nil, errors.New("token has no jti claim")
+	}
+
+	return claims, nil
+}
+
+// keyfunc resolves the verification key of a token from its kid header.
+func (j *jwtService) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, usable := j.key(kid)
+	if !usable && j.reloadForUnknownKey() {
+		key, usable = j.key(kid)
+	}
+	if !usable {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	// Pastikan signing method sesuai dengan key, cegah algorithm confusion
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.verifyKey, nil
+}
+
+// key returns the key kid and whether it may still verify tokens.
+func (j *jwtService) key(kid string) (*jwtKey, bool) {
+	// retireAt diubah oleh RotateKeys, jadi harus dibaca selama lock dipegang
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	key, ok := j.keys[kid]
+	return key, ok && !key.retired(j.now())
+}
+
+// reloadForUnknownKey reloads the key directory when a token names a kid this instance does not
+// know, which happens right after another instance rotated. It reloads at most once a second, so
+// tokens with made-up kids cannot keep the service reading the directory.
+func (j *jwtService) reloadForUnknownKey() bool {
+	if j.keyDirectory == "" {
+		return false
+	}
+	j.mu.RLock()
+	recent := time.Since(j.lastReload) < time.Second
+	j.mu.RUnlock()
+	if recent {
+		return false
+	}
+
+	if err := j.reloadKeys(); err != nil {
+		slog.Error("failed to reload jwt key directory", slog.Any("error", err))
+		return false
+	}
+	return true
+}
+
+// reloadKeys adds the keys written to the key directory since it was last read and takes the
+// newest one as signing key, unless SigningKeyID pins the signing key.
+func (j *jwtService) reloadKeys() error {
+	keys, err := loadKeyDirectory(j.keyDirectory, j.overlap)
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Pastikan signing method sesuai
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.lastReload = time.Now()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		current, ok := j.keys[key.id]
+		if !ok {
+			j.keys[key.id] = key
+			continue
 		}
-		return j.secretKey, nil
-	})
+		if key.retireAt != nil {
+			current.retireAt = key.retireAt
+		}
+	}
 
+	if j.pinned || len(keys) == 0 {
+		return nil
+	}
+	newest := j.keys[keys[len(keys)-1].id]
+	if newest == j.signingKey || newest.signKey == nil || !newest.createdAt.After(j.signingKey.createdAt) {
+		return nil
+	}
+	if j.signingKey.retireAt == nil {
+		retireAt := newest.createdAt.Add(j.overlap)
+		j.signingKey.retireAt = &retireAt
+	}
+	j.signingKey = newest
+	slog.Info("jwt signing key reloaded", slog.String("kid", newest.id))
+
+	return nil
+}
+
+// parserOptions returns the options every token must be parsed with (algorithms, issuer, audience).
+func (j *jwtService) parserOptions() []jwt.ParserOption {
+	j.mu.RLock()
+	methods := make([]string, 0, len(j.keys))
+	for _, key := range j.keys {
+		methods = append(methods, key.method.Alg())
+	}
+	j.mu.RUnlock()
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(j.now),
+	}
+	if j.issuer != "" {
+		options = append(options, jwt.WithIssuer(j.issuer))
+	}
+	if len(j.audience) > 0 {
+		options = append(options, jwt.WithAudience(j.audience...))
+	}
+
+	return options
+}
+
+func (j *jwtService) JWKS() JSONWebKeySet {
+	now := j.now()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	for _, key := range j.keys {
+		if key.retired(now) {
+			continue
+		}
+		if jwk, ok := key.toJWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// RotateKeys makes a freshly generated key the signing key. The previous signing key stays
+// available for verification during the overlap window, so tokens it signed keep working.
+func (j *jwtService) RotateKeys() error {
+	key, content, err := generateJwtKey(j.rotation.Algorithm)
 	if err != nil {
-		return 0, err
+		return err
 	}
+	key.createdAt = j.now()
 
-	if !token.Valid {
-		return 0, errors.New("invalid token")
+	if j.keyDirectory != "" {
+		if err := writeKeyFile(j.keyDirectory, key.id, content); err != nil {
+			return err
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.signingKey != nil {
+		retireAt := key.createdAt.Add(j.overlap)
+		j.signingKey.retireAt = &retireAt
+	}
+	j.keys[key.id] = key
+	j.signingKey = key
+
+	slog.Info("jwt signing key rotated", slog.String("kid", key.id))
+
+	return nil
+}
+
+func (j *jwtService) RunKeyRotation(ctx context.Context) {
+	if !j.rotation.Enabled || j.rotation.IntervalInSecond <= 0 {
+		return
+	}
+
+	interval := time.Duration(j.rotation.IntervalInSecond) * time.Second
+
+	j.mu.RLock()
+	age := j.now().Sub(j.signingKey.createdAt)
+	j.mu.RUnlock()
+
+	// key dari directory bisa saja sudah lewat jadwal rotasi saat service start
+	wait := interval - age
+	if wait < 0 {
+		wait = 0
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := j.RotateKeys(); err != nil {
+				slog.ErrorContext(ctx, "failed to rotate jwt signing key", slog.Any("error", err))
+			}
+			j.pruneKeys(j.now())
+			timer.Reset(interval)
+		}
+	}
+}
+
+func (j *jwtService) RunKeyReload(ctx context.Context) {
+	if j.keyDirectory == "" || j.reloadEvery <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.reloadEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.reloadKeys(); err != nil {
+				slog.ErrorContext(ctx, "failed to reload jwt key directory", slog.Any("error", err))
+			}
+			j.pruneKeys(j.now())
+		}
 	}
+}
+
+// pruneKeys drops keys whose overlap window has passed, including their files in the key directory.
+func (j *jwtService) pruneKeys(now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
 
-	return claims.UserID, nil
+	for id, key := range j.keys {
+		if !key.retired(now) {
+			continue
+		}
+		delete(j.keys, id)
+		if j.keyDirectory != "" {
+			err := os.Remove(filepath.Join(j.keyDirectory, id+".pem"))
+			if err != nil && !os.IsNotExist(err) {
+				slog.Error("failed to remove retired jwt key", slog.String("kid", id), slog.Any("error", err))
+			}
+		}
+	}
 }
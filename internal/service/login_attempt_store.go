@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+// AttemptStore keeps failed-login counters. The in-memory store is enough for a single instance;
+// the database store shares the counters between instances.
+type AttemptStore interface {
+	// Get returns nil when the key has no counter.
+	Get(ctx context.Context, key string) (*model.LoginAttempt, error)
+	// Increment counts an attempt and returns the counter; counters older than window restart at one.
+	// A key locked at now is returned unchanged, so rejected attempts do not extend the count.
+	Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*model.LoginAttempt, error)
+	// Decrement takes back one attempt counted by Increment.
+	Decrement(ctx context.Context, key string) error
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type memoryAttemptStore struct {
+	mu        sync.Mutex
+	attempts  map[string]*model.LoginAttempt
+	lastPrune time.Time
+}
+
+func NewMemoryAttemptStore() AttemptStore {
+	return &memoryAttemptStore{attempts: make(map[string]*model.LoginAttempt), lastPrune: time.Now()}
+}
+
+func (s *memoryAttemptStore) Get(_ context.Context, key string) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *memoryAttemptStore) Increment(_ context.Context, key string, now time.Time, window time.Duration) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now, window)
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		attempt = &model.LoginAttempt{Key: key}
+		s.attempts[key] = attempt
+	}
+	if attempt.LockedUntil == nil || !attempt.LockedUntil.After(now) {
+		if attempt.Failures == 0 || attempt.FirstFailedAt.Before(now.Add(-window)) {
+			attempt.Failures = 0
+			attempt.FirstFailedAt = now
+		}
+		attempt.Failures++
+	}
+
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *memoryAttemptStore) Decrement(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.attempts[key]; ok && attempt.Failures > 0 {
+		attempt.Failures--
+	}
+	return nil
+}
+
+func (s *memoryAttemptStore) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.attempts[key]; ok {
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+func (s *memoryAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune drops stale counters so the map does not grow with every address that ever failed once.
+func (s *memoryAttemptStore) prune(now time.Time, window time.Duration) {
+	if now.Sub(s.lastPrune) < window {
+		return
+	}
+	s.lastPrune = now
+
+	for key, attempt := range s.attempts {
+		locked := attempt.LockedUntil != nil && attempt.LockedUntil.After(now)
+		if !locked && attempt.FirstFailedAt.Before(now.Add(-window)) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+type databaseAttemptStore struct {
+	db   *sql.DB
+	repo repository.LoginAttemptRepository
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewDatabaseAttemptStore(db *sql.DB, loginAttemptRepository repository.LoginAttemptRepository) AttemptStore {
+	return &databaseAttemptStore{db: db, repo: loginAttemptRepository, lastPrune: time.Now()}
+}
+
+func (s *databaseAttemptStore) Get(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	attempt, err := s.repo.Get(ctx, tx, key)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, nil
+	}
+	return attempt, err
+}
+
+func (s *databaseAttemptStore) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*model.LoginAttempt, error) {
+	s.prune(ctx, now, window)
+
+	var attempt *model.LoginAttempt
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		attempt, err = s.repo.Increment(ctx, tx, key, now, now.Add(-window))
+		return err
+	})
+	return attempt, err
+}
+
+func (s *databaseAttemptStore) Decrement(ctx context.Context, key string) error {
+	return inTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.repo.Decrement(ctx, tx, key)
+	})
+}
+
+func (s *databaseAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return inTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.repo.Lock(ctx, tx, key, until)
+	})
+}
+
+func (s *databaseAttemptStore) Reset(ctx context.Context, key string) error {
+	return inTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.repo.Delete(ctx, tx, key)
+	})
+}
+
+// prune drops stale counters at most once per window per instance, like the memory store, so every
+// unknown email or address does not leave a row behind; errors are only logged because the login
+// itself does not depend on it.
+func (s *databaseAttemptStore) prune(ctx context.Context, now time.Time, window time.Duration) {
+	s.mu.Lock()
+	if now.Sub(s.lastPrune) < window {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.mu.Unlock()
+
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		_, err := s.repo.DeleteStale(ctx, tx, now.Add(-window))
+		return err
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to prune login attempts", slog.Any("error", err))
+	}
+}
+
+// inTx runs fn in a transaction on db; the database stores use it instead of database.DB.RunInTx
+// because they always write to the primary and never retry.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+)
+
+// fakeLoginAttemptRepository keeps the counters in memory and applies DeleteStale like the query.
+type fakeLoginAttemptRepository struct {
+	repository.LoginAttemptRepository
+	mu       sync.Mutex
+	attempts map[string]*model.LoginAttempt
+}
+
+func (r *fakeLoginAttemptRepository) Increment(_ context.Context, _ *sql.Tx, key string, now, _ time.Time) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &model.LoginAttempt{Key: key, FirstFailedAt: now}
+		r.attempts[key] = attempt
+	}
+	attempt.Failures++
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepository) DeleteStale(_ context.Context, _ *sql.Tx, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, attempt := range r.attempts {
+		if attempt.FirstFailedAt.Before(before) && (attempt.LockedUntil == nil || attempt.LockedUntil.Before(before)) {
+			delete(r.attempts, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func Test_databaseAttemptStore_prune(t *testing.T) {
+	const window = 15 * time.Minute
+	ctx := context.Background()
+	start := time.Now()
+	lockedUntil := start.Add(time.Hour)
+
+	db, _ := databasetest.New()
+	repo := &fakeLoginAttemptRepository{attempts: map[string]*model.LoginAttempt{
+		"account:stale@example.com":  {Key: "account:stale@example.com", Failures: 1, FirstFailedAt: start},
+		"account:locked@example.com": {Key: "account:locked@example.com", Failures: 8, FirstFailedAt: start, LockedUntil: &lockedUntil},
+	}}
+	s := NewDatabaseAttemptStore(db.Primary(), repo)
+
+	// belum lewat satu window sejak store dibuat, tidak ada yang dihapus
+	if _, err := s.Increment(ctx, "ip:10.0.0.1", start.Add(time.Minute), window); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.attempts) != 3 {
+		t.Fatalf("attempts = %d, want 3 before a window passed", len(repo.attempts))
+	}
+
+	if _, err := s.Increment(ctx, "ip:10.0.0.2", start.Add(window+30*time.Second), window); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{
+		"account:stale@example.com":  false,
+		"account:locked@example.com": true,
+		"ip:10.0.0.1":                true,
+		"ip:10.0.0.2":                true,
+	} {
+		if _, ok := repo.attempts[key]; ok != want {
+			t.Errorf("attempt %s kept = %v, want %v", key, ok, want)
+		}
+	}
+}
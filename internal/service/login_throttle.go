@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+const (
+	LoginSubjectAccount = "account"
+	LoginSubjectIP      = "ip"
+)
+
+// LoginSubject is what failed logins are counted against: the targeted account or the client IP.
+type LoginSubject struct {
+	Kind  string
+	Value string
+}
+
+func AccountSubject(email string) LoginSubject {
+	return LoginSubject{Kind: LoginSubjectAccount, Value: strings.ToLower(strings.TrimSpace(email))}
+}
+
+func IPSubject(ip string) LoginSubject {
+	return LoginSubject{Kind: LoginSubjectIP, Value: ip}
+}
+
+func (s LoginSubject) key() string {
+	return s.Kind + ":" + s.Value
+}
+
+// TooManyAttemptsError is returned while a subject is delayed or locked out.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", utils.ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *TooManyAttemptsError) Unwrap() error {
+	return utils.ErrTooManyAttempts
+}
+
+// LoginThrottle applies progressive delays and temporary lockouts to failed logins. Every attempt is
+// counted by Begin before the credentials are checked, so parallel requests cannot all pass the check
+// before the first failure is recorded; Succeed and Release take the attempt back.
+type LoginThrottle interface {
+	// Begin counts an attempt for every subject. It returns a *TooManyAttemptsError, without
+	// counting, when one of the subjects may not try yet.
+	Begin(ctx context.Context, subjects ...LoginSubject) error
+	// Fail keeps the attempt counted by Begin as a failure and returns the subjects that just
+	// reached the lockout.
+	Fail(ctx context.Context, subjects ...LoginSubject) ([]LoginSubject, error)
+	// Succeed clears the account counters. For the other subjects only the attempt counted by
+	// Begin is taken back, so one valid account cannot be used to reset the budget of an address.
+	Succeed(ctx context.Context, subjects ...LoginSubject) error
+	// Release takes back the attempt counted by Begin when the credentials were never checked,
+	// e.g. because the database failed.
+	Release(ctx context.Context, subjects ...LoginSubject) error
+}
+
+type loginThrottle struct {
+	config *config.LoginProtectionConfig
+	store  AttemptStore
+	now    func() time.Time
+}
+
+func NewLoginThrottle(config *config.LoginProtectionConfig, store AttemptStore) LoginThrottle {
+	return &loginThrottle{config: config, store: store, now: time.Now}
+}
+
+func (l *loginThrottle) Begin(ctx context.Context, subjects ...LoginSubject) error {
+	now := l.now()
+
+	var (
+		retryAfter time.Duration
+		counted    []LoginSubject
+	)
+	for _, subject := range subjects {
+		attempt, err := l.store.Increment(ctx, subject.key(), now, l.window())
+		if err != nil {
+			_ = l.Release(ctx, counted...)
+			return err
+		}
+
+		maxAttempts := l.maxAttempts(subject)
+		switch {
+		case attempt.LockedUntil != nil && attempt.LockedUntil.After(now):
+			retryAfter = max(retryAfter, attempt.LockedUntil.Sub(now))
+		case maxAttempts > 0 && attempt.Failures > maxAttempts:
+			// percobaan paralel sudah menghabiskan jatah sebelum lockout sempat dipasang
+			counted = append(counted, subject)
+			retryAfter = max(retryAfter, l.seconds(l.config.LockoutInSecond, 15*time.Minute))
+		default:
+			counted = append(counted, subject)
+		}
+	}
+
+	if retryAfter > 0 {
+		if err := l.Release(ctx, counted...); err != nil {
+			return err
+		}
+		return &TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (l *loginThrottle) Fail(ctx context.Context, subjects ...LoginSubject) ([]LoginSubject, error) {
+	now := l.now()
+	var lockedOut []LoginSubject
+
+	for _, subject := range subjects {
+		attempt, err := l.store.Get(ctx, subject.key())
+		if err != nil {
+			return lockedOut, err
+		}
+		if attempt == nil {
+			continue
+		}
+		alreadyLocked := attempt.LockedUntil != nil && attempt.LockedUntil.After(now)
+
+		maxAttempts := l.maxAttempts(subject)
+		var delay time.Duration
+		switch {
+		case maxAttempts > 0 && attempt.Failures >= maxAttempts:
+			delay = l.seconds(l.config.LockoutInSecond, 15*time.Minute)
+			// hanya dicatat sekali, saat batas pertama kali tercapai
+			if !alreadyLocked {
+				lockedOut = append(lockedOut, subject)
+			}
+		case subject.Kind == LoginSubjectAccount && attempt.Failures > l.config.FreeAttempts:
+			delay = l.progressiveDelay(attempt.Failures - l.config.FreeAttempts)
+		}
+
+		if delay > 0 {
+			err = l.store.Lock(ctx, subject.key(), now.Add(delay))
+			if err != nil {
+				return lockedOut, err
+			}
+		}
+	}
+
+	return lockedOut, nil
+}
+
+func (l *loginThrottle) Succeed(ctx context.Context, subjects ...LoginSubject) error {
+	for _, subject := range subjects {
+		var err error
+		if subject.Kind == LoginSubjectAccount {
+			err = l.store.Reset(ctx, subject.key())
+		} else {
+			err = l.store.Decrement(ctx, subject.key())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loginThrottle) Release(ctx context.Context, subjects ...LoginSubject) error {
+	for _, subject := range subjects {
+		if err := l.store.Decrement(ctx, subject.key()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loginThrottle) maxAttempts(subject LoginSubject) int {
+	if subject.Kind == LoginSubjectIP {
+		return l.config.IPMaxAttempts
+	}
+	return l.config.AccountMaxAttempts
+}
+
+func (l *loginThrottle) window() time.Duration {
+	return l.seconds(l.config.WindowInSecond, 15*time.Minute)
+}
+
+// progressiveDelay doubles from the base delay for every failure past the free attempts.
+func (l *loginThrottle) progressiveDelay(extraFailures int) time.Duration {
+	delay := l.seconds(l.config.BaseDelayInSecond, time.Second)
+	maxDelay := l.seconds(l.config.MaxDelayInSecond, 30*time.Second)
+
+	for i := 1; i < extraFailures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+func (l *loginThrottle) seconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * time.Second
+}
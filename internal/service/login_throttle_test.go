@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+func Test_loginThrottle_Fail(t *testing.T) {
+	cfg := &config.LoginProtectionConfig{
+		WindowInSecond:     900,
+		FreeAttempts:       3,
+		BaseDelayInSecond:  1,
+		MaxDelayInSecond:   4,
+		AccountMaxAttempts: 8,
+		IPMaxAttempts:      20,
+		LockoutInSecond:    900,
+	}
+	account := AccountSubject("Jane@Example.com")
+	ip := IPSubject("10.0.0.1")
+
+	tests := []struct {
+		name           string
+		failures       int
+		wantRetryAfter time.Duration
+		wantLockedOut  bool
+	}{
+		{name: "free attempts", failures: 3, wantRetryAfter: 0},
+		{name: "first delay", failures: 4, wantRetryAfter: time.Second},
+		{name: "delay doubles", failures: 6, wantRetryAfter: 4 * time.Second},
+		{name: "delay is capped", failures: 7, wantRetryAfter: 4 * time.Second},
+		{name: "lockout", failures: 8, wantRetryAfter: 900 * time.Second, wantLockedOut: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			l := &loginThrottle{config: cfg, store: NewMemoryAttemptStore(), now: func() time.Time { return now }}
+			ctx := context.Background()
+
+			var lockedOut []LoginSubject
+			for i := 0; i < tt.failures; i++ {
+				// tunggu sampai delay dari kegagalan sebelumnya habis
+				now = now.Add(5 * time.Second)
+				if err := l.Begin(ctx, account, ip); err != nil {
+					t.Fatalf("Begin() error = %v", err)
+				}
+				got, err := l.Fail(ctx, account, ip)
+				if err != nil {
+					t.Fatalf("Fail() error = %v", err)
+				}
+				lockedOut = append(lockedOut, got...)
+			}
+
+			err := l.Begin(ctx, account, ip)
+			var tooMany *TooManyAttemptsError
+			if tt.wantRetryAfter == 0 {
+				if err != nil {
+					t.Errorf("Begin() error = %v, want nil", err)
+				}
+			} else if !errors.As(err, &tooMany) || !errors.Is(err, utils.ErrTooManyAttempts) || tooMany.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("Begin() error = %v, want retry after %v", err, tt.wantRetryAfter)
+			}
+
+			if gotLockedOut := len(lockedOut) == 1 && lockedOut[0] == account; gotLockedOut != tt.wantLockedOut {
+				t.Errorf("Fail() locked out = %v, want account locked out %v", lockedOut, tt.wantLockedOut)
+			}
+		})
+	}
+}
+
+func Test_loginThrottle_Succeed(t *testing.T) {
+	cfg := &config.LoginProtectionConfig{FreeAttempts: 1, AccountMaxAttempts: 10, IPMaxAttempts: 2}
+	now := time.Now()
+	l := &loginThrottle{config: cfg, store: NewMemoryAttemptStore(), now: func() time.Time { return now }}
+	ctx := context.Background()
+	account := AccountSubject("jane@example.com")
+	ip := IPSubject("10.0.0.1")
+
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Minute)
+		if err := l.Begin(ctx, account, ip); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.Fail(ctx, account, ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Succeed(ctx, account, ip); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Begin(ctx, account); err != nil {
+		t.Errorf("Begin(account) error = %v, want counter reset", err)
+	}
+	if err := l.Begin(ctx, ip); !errors.Is(err, utils.ErrTooManyAttempts) {
+		t.Errorf("Begin(ip) error = %v, want ip to stay locked", err)
+	}
+}
+
+func Test_loginThrottle_BeginParallel(t *testing.T) {
+	cfg := &config.LoginProtectionConfig{FreeAttempts: 3, AccountMaxAttempts: 3, IPMaxAttempts: 100}
+	l := NewLoginThrottle(cfg, NewMemoryAttemptStore())
+	ctx := context.Background()
+	account := AccountSubject("jane@example.com")
+	ip := IPSubject("10.0.0.1")
+
+	// tidak ada Fail di antaranya: semua percobaan berjalan bersamaan sebelum password dicek
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Begin(ctx, account, ip); err == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != cfg.AccountMaxAttempts {
+		t.Errorf("Begin() allowed %d parallel attempts, want %d", allowed, cfg.AccountMaxAttempts)
+	}
+
+	// percobaan yang ditolak karena akun tidak ikut menghabiskan jatah ip
+	attempt, err := l.(*loginThrottle).store.Get(ctx, ip.key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt.Failures != cfg.AccountMaxAttempts {
+		t.Errorf("ip failures = %d, want %d", attempt.Failures, cfg.AccountMaxAttempts)
+	}
+
+	if err := l.Release(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Begin(ctx, account); err != nil {
+		t.Errorf("Begin() after Release error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails. SMTP is used in deployed environments, the file and
+// log implementations are meant for local development.
+type Mailer interface {
+	Send(ctx context.Context, message MailMessage) error
+}
+
+func NewMailer(config *config.MailConfig) (Mailer, error) {
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("mail: invalid From address: %w", err)
+	}
+
+	switch strings.ToLower(config.Driver) {
+	case "smtp":
+		if config.Smtp.Host == "" || config.Smtp.Port == "" {
+			return nil, errors.New("mail: Smtp.Host and Smtp.Port are required")
+		}
+		return &smtpMailer{config: config}, nil
+	case "file":
+		if config.FilePath == "" {
+			return nil, errors.New("mail: FilePath is required for the file driver")
+		}
+		return &fileMailer{from: config.From, path: config.FilePath}, nil
+	case "", "log":
+		return &logMailer{from: config.From}, nil
+	default:
+		return nil, fmt.Errorf("mail: unsupported driver %q", config.Driver)
+	}
+}
+
+func formatMailMessage(from string, message MailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + message.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+func validateMailMessage(message MailMessage) error {
+	// cegah header injection lewat alamat atau subject
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return errors.New("mail: header values must not contain line breaks")
+	}
+	if _, err := mail.ParseAddress(message.To); err != nil {
+		return fmt.Errorf("mail: invalid recipient: %w", err)
+	}
+	return nil
+}
+
+type smtpMailer struct {
+	config *config.MailConfig
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message MailMessage) error {
+	if err := validateMailMessage(message); err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(m.config.From)
+
+	var auth smtp.Auth
+	if m.config.Smtp.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Smtp.Username, m.config.Smtp.Password, m.config.Smtp.Host)
+	}
+
+	// smtp.SendMail tidak menerima context, jadi jalankan di goroutine agar timeout tetap dihormati
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(m.config.Smtp.Host, m.config.Smtp.Port), auth,
+			from.Address, []string{message.To}, formatMailMessage(m.config.From, message))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type fileMailer struct {
+	from string
+	path string
+	mu   sync.Mutex
+}
+
+func (m *fileMailer) Send(_ context.Context, message MailMessage) error {
+	if err := validateMailMessage(message); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(formatMailMessage(m.from, message), []byte("\r\n")...))
+	return err
+}
+
+type logMailer struct {
+	from string
+}
+
+func (m *logMailer) Send(ctx context.Context, message MailMessage) error {
+	if err := validateMailMessage(message); err != nil {
+		return err
+	}
+	// body tidak ikut di-log karena berisi token verifikasi dan reset password
+	slog.InfoContext(ctx, "mail sent to log",
+		slog.String("from", m.from),
+		slog.String("to", message.To),
+		slog.String("subject", message.Subject),
+	)
+	return nil
+}
+
+// MailQueue is a Mailer that hands messages to a background worker, so a request never waits for
+// the mail server and its response time does not tell whether a mail was sent at all.
+type MailQueue struct {
+	mailer   Mailer
+	messages chan queuedMail
+}
+
+type queuedMail struct {
+	ctx     context.Context
+	message MailMessage
+}
+
+// mailSendTimeout bounds a single delivery by the worker.
+const mailSendTimeout = 30 * time.Second
+
+func NewMailQueue(mailer Mailer, size int) *MailQueue {
+	return &MailQueue{mailer: mailer, messages: make(chan queuedMail, size)}
+}
+
+// Send validates the message and queues it. A full queue drops the message instead of blocking;
+// the error is only logged because returning it would reveal the recipient to the caller.
+func (q *MailQueue) Send(ctx context.Context, message MailMessage) error {
+	if err := validateMailMessage(message); err != nil {
+		return err
+	}
+
+	// request ID dan trace tetap ikut ke log worker, tapi pengiriman tidak ikut batal bersama request
+	queued := queuedMail{ctx: context.WithoutCancel(ctx), message: message}
+	select {
+	case q.messages <- queued:
+	default:
+		slog.ErrorContext(ctx, "mail queue is full, message dropped", slog.String("subject", message.Subject))
+	}
+	return nil
+}
+
+// Run delivers queued messages until ctx is done, then delivers what is left in the queue.
+func (q *MailQueue) Run(ctx context.Context) {
+	for {
+		select {
+		case queued := <-q.messages:
+			q.deliver(queued)
+		case <-ctx.Done():
+			for {
+				select {
+				case queued := <-q.messages:
+					q.deliver(queued)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *MailQueue) deliver(queued queuedMail) {
+	ctx, cancel := context.WithTimeout(queued.ctx, mailSendTimeout)
+	defer cancel()
+
+	if err := q.mailer.Send(ctx, queued.message); err != nil {
+		slog.ErrorContext(ctx, "failed to send mail", slog.String("subject", queued.message.Subject), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingMailer blocks every Send until release is closed.
+type blockingMailer struct {
+	release chan struct{}
+	mu      sync.Mutex
+	sent    []MailMessage
+}
+
+func (m *blockingMailer) Send(_ context.Context, message MailMessage) error {
+	<-m.release
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+func TestMailQueue(t *testing.T) {
+	transport := &blockingMailer{release: make(chan struct{})}
+	queue := NewMailQueue(transport, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(done)
+	}()
+
+	message := MailMessage{To: "jane@example.com", Subject: "Reset your password", Body: "link"}
+	start := time.Now()
+	if err := queue.Send(context.Background(), message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Send() waited %v for the mail server", elapsed)
+	}
+
+	if err := queue.Send(context.Background(), MailMessage{To: "not an address", Subject: "x"}); err == nil {
+		t.Error("Send() of an invalid recipient error = nil")
+	}
+
+	cancel()
+	close(transport.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after ctx was cancelled")
+	}
+	if len(transport.sent) != 1 || transport.sent[0] != message {
+		t.Errorf("sent = %+v, want the queued message", transport.sent)
+	}
+}
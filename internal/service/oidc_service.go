@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDCService runs the authorization code flow with PKCE against the configured identity provider.
+type OIDCService interface {
+	ProviderName() string
+	AutoProvision() bool
+	StateTTL() time.Duration
+	// Begin returns the provider's authorization URL and a signed value carrying state, nonce and
+	// PKCE verifier, to be kept in a cookie until the callback.
+	Begin(ctx context.Context) (authURL string, stateCookie string, err error)
+	// Complete checks the callback against the state cookie, redeems the code and verifies the ID token.
+	Complete(ctx context.Context, stateCookie, state, code string) (*model.ExternalIdentity, error)
+}
+
+type oidcService struct {
+	config *config.OidcConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+	oauth2   *oauth2.Config
+}
+
+func NewOIDCService(config *config.OidcConfig) (OIDCService, error) {
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("oidc: IssuerURL, ClientID and RedirectURL are required")
+	}
+	if len(config.StateSecret) < 16 {
+		return nil, errors.New("oidc: StateSecret must be at least 16 characters")
+	}
+	return &oidcService{config: config}, nil
+}
+
+type oidcState struct {
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+func (o *oidcService) ProviderName() string {
+	return o.config.ProviderName
+}
+
+func (o *oidcService) AutoProvision() bool {
+	return o.config.AutoProvision
+}
+
+func (o *oidcService) StateTTL() time.Duration {
+	if o.config.StateTTLInSecond <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(o.config.StateTTLInSecond) * time.Second
+}
+
+// init discovers the provider on first use, so the service can start while the provider is unreachable.
+func (o *oidcService) init(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider != nil {
+		return nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, o.config.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	scopes := o.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+
+	o.provider = provider
+	o.verifier = provider.Verifier(&oidc.Config{ClientID: o.config.ClientID})
+	o.oauth2 = &oauth2.Config{
+		ClientID:     o.config.ClientID,
+		ClientSecret: o.config.ClientSecret,
+		RedirectURL:  o.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+
+	return nil
+}
+
+func (o *oidcService) Begin(ctx context.Context) (string, string, error) {
+	if err := o.init(ctx); err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	stateCookie, err := o.signState(oidcState{
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: time.Now().Add(o.StateTTL()).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := o.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+
+	return authURL, stateCookie, nil
+}
+
+func (o *oidcService) Complete(ctx context.Context, stateCookie, state, code string) (*model.ExternalIdentity, error) {
+	if err := o.init(ctx); err != nil {
+		return nil, err
+	}
+
+	saved, err := o.verifyState(stateCookie)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(saved.State), []byte(state)) != 1 {
+		return nil, fmt.Errorf("%w: state mismatch", utils.ErrInvalidToken)
+	}
+	if code == "" {
+		return nil, fmt.Errorf("%w: missing authorization code", utils.ErrInvalidToken)
+	}
+
+	token, err := o.oauth2.Exchange(ctx, code, oauth2.VerifierOption(saved.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: code exchange failed: %v", utils.ErrInvalidToken, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: token response has no id_token", utils.ErrInvalidToken)
+	}
+
+	idToken, err := o.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidToken, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(saved.Nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", utils.ErrInvalidToken)
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &model.ExternalIdentity{
+		Provider:      o.config.ProviderName,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (o *oidcService) signState(state oidcState) (string, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + o.sign(encoded), nil
+}
+
+func (o *oidcService) verifyState(value string) (*oidcState, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(o.sign(encoded))) {
+		return nil, fmt.Errorf("%w: invalid state cookie", utils.ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid state cookie", utils.ErrInvalidToken)
+	}
+
+	var state oidcState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return nil, fmt.Errorf("%w: invalid state cookie", utils.ErrInvalidToken)
+	}
+	if time.Now().Unix() > state.ExpiresAt {
+		return nil, fmt.Errorf("%w: login session expired", utils.ErrInvalidToken)
+	}
+
+	return &state, nil
+}
+
+func (o *oidcService) sign(value string) string {
+	mac := hmac.New(sha256.New, []byte(o.config.StateSecret))
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
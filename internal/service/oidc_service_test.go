@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockOIDCProvider is a minimal OpenID provider: discovery, JWKS and a token endpoint that
+// enforces PKCE (S256) and echoes the nonce of the authorization request into the ID token.
+type mockOIDCProvider struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	nonce         string
+	codeChallenge string
+}
+
+func newMockOIDCProvider(t *testing.T, clientID string) *mockOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockOIDCProvider{key: key, clientID: clientID, codes: make(map[string]mockAuthorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk, _ := (&jwtKey{id: "mock-1", method: jwt.SigningMethodRS256, verifyKey: &key.PublicKey}).toJWK()
+		json.NewEncoder(w).Encode(JSONWebKeySet{Keys: []JSONWebKey{jwk}})
+	})
+	mux.HandleFunc("/token", m.token)
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// authorize simulates the user approving the login at the provider and returns the callback code.
+func (m *mockOIDCProvider) authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization request without PKCE: %s", authURL)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	code = "code-" + query.Get("state")
+	m.codes[code] = mockAuthorization{nonce: query.Get("nonce"), codeChallenge: query.Get("code_challenge")}
+
+	return code, query.Get("state")
+}
+
+func (m *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	authorization, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != authorization.codeChallenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "subject-123",
+		"aud":            m.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          authorization.nonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+	})
+	idToken.Header["kid"] = "mock-1"
+	signed, _ := idToken.SignedString(m.key)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+func Test_oidcService_Complete(t *testing.T) {
+	provider := newMockOIDCProvider(t, "go-management-project")
+
+	o, err := NewOIDCService(&config.OidcConfig{
+		ProviderName: "mock",
+		IssuerURL:    provider.server.URL,
+		ClientID:     "go-management-project",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:9999/auth/oidc/callback",
+		StateSecret:  "a-long-enough-state-secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	begin := func() (cookie, code, state string) {
+		authURL, cookie, err := o.Begin(context.Background())
+		if err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+		code, state = provider.authorize(t, authURL)
+		return cookie, code, state
+	}
+
+	type args struct {
+		stateCookie string
+		state       string
+		code        string
+	}
+	tests := []struct {
+		name    string
+		args    func() args
+		wantErr bool
+	}{
+		{
+			name: "valid callback",
+			args: func() args {
+				cookie, code, state := begin()
+				return args{stateCookie: cookie, state: state, code: code}
+			},
+			wantErr: false,
+		},
+		{
+			name: "state mismatch",
+			args: func() args {
+				cookie, code, _ := begin()
+				return args{stateCookie: cookie, state: "forged", code: code}
+			},
+			wantErr: true,
+		},
+		{
+			name: "tampered state cookie",
+			args: func() args {
+				cookie, code, state := begin()
+				return args{stateCookie: "x" + cookie, state: state, code: code}
+			},
+			wantErr: true,
+		},
+		{
+			name: "code redeemed with another login's verifier",
+			args: func() args {
+				_, code, _ := begin()
+				otherCookie, _, otherState := begin()
+				return args{stateCookie: otherCookie, state: otherState, code: code}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.args()
+			got, err := o.Complete(context.Background(), a.stateCookie, a.state, a.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Complete() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Provider != "mock" || got.Subject != "subject-123" || got.Email != "jane@example.com" || !got.EmailVerified {
+				t.Errorf("Complete() got = %+v", got)
+			}
+		})
+	}
+}
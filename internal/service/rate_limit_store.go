@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/repository"
+)
+
+type RateLimitRule struct {
+	Limit  int
+	Period time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is the wait until the next token when the request was rejected.
+	RetryAfter time.Duration
+	// ResetAfter is the wait until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// RateLimitStore holds token buckets. The in-memory store only limits a single instance; the
+// database store shares the buckets between instances. Take must be atomic per key.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens    float64
+	period    time.Duration
+	updatedAt time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*tokenBucket), lastPrune: time.Now(), now: time.Now}
+}
+
+func (s *memoryRateLimitStore) Take(_ context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(rule.Limit), period: rule.Period, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	var result RateLimitResult
+	bucket.tokens, result = takeToken(bucket.tokens, bucket.updatedAt, now, rule)
+	bucket.updatedAt = now
+
+	return result, nil
+}
+
+// prune drops buckets that have been idle long enough to be full again.
+func (s *memoryRateLimitStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) >= bucket.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+type databaseRateLimitStore struct {
+	db   *sql.DB
+	repo repository.RateLimitBucketRepository
+	now  func() time.Time
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewDatabaseRateLimitStore(db *sql.DB, rateLimitBucketRepository repository.RateLimitBucketRepository) RateLimitStore {
+	return &databaseRateLimitStore{db: db, repo: rateLimitBucketRepository, now: time.Now, lastPrune: time.Now()}
+}
+
+func (s *databaseRateLimitStore) Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	now := s.now()
+	s.prune(ctx, now)
+
+	var result RateLimitResult
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		// row lock membuat request paralel untuk key yang sama antre, juga dari instance lain
+		bucket, err := s.repo.GetForUpdate(ctx, tx, key, float64(rule.Limit), now)
+		if err != nil {
+			return err
+		}
+
+		bucket.Tokens, result = takeToken(bucket.Tokens, bucket.UpdatedAt, now, rule)
+		bucket.UpdatedAt = now
+		bucket.FullAt = now.Add(result.ResetAfter)
+		return s.repo.Update(ctx, tx, bucket)
+	})
+	return result, err
+}
+
+// prune drops full buckets at most once a minute per instance; errors are only logged because the
+// request itself does not depend on it.
+func (s *databaseRateLimitStore) prune(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPrune) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.mu.Unlock()
+
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		_, err := s.repo.DeleteFull(ctx, tx, now)
+		return err
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to prune rate limit buckets", slog.Any("error", err))
+	}
+}
+
+// takeToken refills tokens for the time since updatedAt and takes one if there is a whole token
+// left. Both stores use it so they limit the same way.
+func takeToken(tokens float64, updatedAt, now time.Time, rule RateLimitRule) (float64, RateLimitResult) {
+	limit := float64(rule.Limit)
+	ratePerSecond := limit / rule.Period.Seconds()
+
+	tokens = min(limit, tokens+max(now.Sub(updatedAt).Seconds(), 0)*ratePerSecond)
+
+	result := RateLimitResult{}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / ratePerSecond)
+	}
+	result.Remaining = int(tokens)
+	result.ResetAfter = secondsToDuration((limit - tokens) / ratePerSecond)
+
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_memoryRateLimitStore_Take(t *testing.T) {
+	rule := RateLimitRule{Limit: 3, Period: 3 * time.Second}
+
+	tests := []struct {
+		name          string
+		requests      int
+		elapsed       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{name: "first request", requests: 1, wantAllowed: true, wantRemaining: 2},
+		{name: "burst uses the whole bucket", requests: 3, wantAllowed: true, wantRemaining: 0},
+		{name: "over the limit", requests: 4, wantAllowed: false, wantRemaining: 0, wantRetry: time.Second},
+		{name: "refilled after waiting", requests: 4, elapsed: time.Second, wantAllowed: true, wantRemaining: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			s := &memoryRateLimitStore{buckets: make(map[string]*tokenBucket), lastPrune: now, now: func() time.Time { return now }}
+
+			var got RateLimitResult
+			for i := 0; i < tt.requests; i++ {
+				if i == tt.requests-1 {
+					now = now.Add(tt.elapsed)
+				}
+				var err error
+				got, err = s.Take(context.Background(), "auth:ip:10.0.0.1", rule)
+				if err != nil {
+					t.Fatalf("Take() error = %v", err)
+				}
+			}
+
+			if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining || got.RetryAfter != tt.wantRetry {
+				t.Errorf("Take() = %+v, want allowed %v remaining %v retry %v", got, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func Test_generateTOTP(t *testing.T) {
+	// test vector SHA1 dari RFC 6238 Appendix B
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "59", unix: 59, want: "94287082"},
+		{name: "1111111109", unix: 1111111109, want: "07081804"},
+		{name: "1111111111", unix: 1111111111, want: "14050471"},
+		{name: "1234567890", unix: 1234567890, want: "89005924"},
+		{name: "2000000000", unix: 2000000000, want: "69279037"},
+		{name: "20000000000", unix: 20000000000, want: "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generateTOTP(key, uint64(tt.unix/totpPeriod), 8); got != tt.want {
+				t.Errorf("generateTOTP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name         string
+		code         string
+		lastUsedStep int64
+		wantStep     int64
+		wantOk       bool
+	}{
+		{name: "current step", code: generateTOTP(key, uint64(step), totpDigits), wantStep: step, wantOk: true},
+		{name: "previous step within skew", code: generateTOTP(key, uint64(step-1), totpDigits), wantStep: step - 1, wantOk: true},
+		{name: "outside skew", code: generateTOTP(key, uint64(step-2), totpDigits), wantOk: false},
+		{name: "replayed code", code: generateTOTP(key, uint64(step), totpDigits), lastUsedStep: step, wantOk: false},
+		{name: "wrong length", code: "12345", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOk := ValidateTOTP(secret, tt.code, now, tt.lastUsedStep)
+			if gotOk != tt.wantOk || (tt.wantOk && gotStep != tt.wantStep) {
+				t.Errorf("ValidateTOTP() = (%v, %v), want (%v, %v)", gotStep, gotOk, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
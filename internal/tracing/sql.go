@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SQLOptions instruments database/sql so every query and every begin, commit and rollback of a
+// transaction gets a span. The query is recorded sanitized, arguments are never recorded.
+func SQLOptions(system attribute.KeyValue) []otelsql.Option {
+	return []otelsql.Option{
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableQuery:         true,
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+		otelsql.WithSpanNameFormatter(func(_ context.Context, method otelsql.Method, query string) string {
+			if operation := sqlOperation(query); operation != "" {
+				return operation
+			}
+			return string(method)
+		}),
+		otelsql.WithAttributesGetter(func(_ context.Context, _ otelsql.Method, query string, _ []driver.NamedValue) []attribute.KeyValue {
+			if query == "" {
+				return nil
+			}
+			return []attribute.KeyValue{
+				semconv.DBOperationName(sqlOperation(query)),
+				semconv.DBQueryText(SanitizeSQL(query)),
+			}
+		}),
+	}
+}
+
+// SanitizeSQL replaces string, dollar-quoted and numeric literals with ?, drops -- comments and
+// collapses whitespace. Placeholders such as $1 and identifiers such as users2 are kept.
+func SanitizeSQL(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	var prev byte
+	space := false
+	write := func(s string) {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+		prev = s[len(s)-1]
+	}
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			// lewati isi literal, termasuk quote yang di-escape ('')
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			write("?")
+		case ch == '$' && !isWordByte(prev) && dollarQuoteTag(query[i:]) != "":
+			// dollar quoting ($$...$$ atau $tag$...$tag$) juga literal, isinya bisa berisi quote
+			tag := dollarQuoteTag(query[i:])
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += len(tag) + end + len(tag) - 1
+			}
+			write("?")
+		case isDigit(ch) && !isWordByte(prev):
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			write("?")
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			space = true
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			space = true
+			prev = ' '
+		default:
+			write(string(ch))
+		}
+	}
+
+	return b.String()
+}
+
+// sqlOperation returns the first keyword of the query, e.g. SELECT or UPDATE.
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// dollarQuoteTag returns the opening $tag$ or $$ at the start of query, or "" when query does not
+// start with one. A tag cannot start with a digit, so placeholders such as $1 are not tags.
+func dollarQuoteTag(query string) string {
+	i := 1
+	for i < len(query) && (query[i] == '_' || (query[i] >= 'a' && query[i] <= 'z') || (query[i] >= 'A' && query[i] <= 'Z') || (i > 1 && isDigit(query[i]))) {
+		i++
+	}
+	if i < len(query) && query[i] == '$' {
+		return query[:i+1]
+	}
+	return ""
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || ch == '$' || isDigit(ch) || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
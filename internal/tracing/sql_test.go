@@ -0,0 +1,35 @@
+package tracing
+
+import "testing"
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name: "placeholders are kept",
+			query: `SELECT id, name
+				FROM projects
+				WHERE id = $1 AND deleted_at IS NULL`,
+			want: "SELECT id, name FROM projects WHERE id = $1 AND deleted_at IS NULL",
+		},
+		{name: "string literal", query: "SELECT id FROM users WHERE status = 'active'", want: "SELECT id FROM users WHERE status = ?"},
+		{name: "escaped quote", query: "SELECT 'it''s' AS x", want: "SELECT ? AS x"},
+		{name: "numeric literal", query: "UPDATE cards SET version = version + 1 WHERE id = $2 LIMIT 10", want: "UPDATE cards SET version = version + ? WHERE id = $2 LIMIT ?"},
+		{name: "decimal literal", query: "SELECT 3.14", want: "SELECT ?"},
+		{name: "identifier with digits", query: "SELECT * FROM users2 t1", want: "SELECT * FROM users2 t1"},
+		{name: "dollar quoted literal", query: "SELECT $$it's 'secret'$$, $1", want: "SELECT ?, $1"},
+		{name: "tagged dollar quoted literal", query: "SELECT $tag$ $$ 'x' $tag$ FROM users WHERE id = $2", want: "SELECT ? FROM users WHERE id = $2"},
+		{name: "unterminated dollar quoted literal", query: "SELECT $a$ secret", want: "SELECT ?"},
+		{name: "comment is dropped", query: "SELECT 1 -- password 'secret'\nFROM dual", want: "SELECT ? FROM dual"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeSQL(tt.query); got != tt.want {
+				t.Errorf("SanitizeSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.TracingConfig
+		wantErr bool
+	}{
+		{name: "otlp", cfg: config.TracingConfig{Exporter: "otlp", Endpoint: "localhost:4318", Insecure: true}},
+		{name: "stdout", cfg: config.TracingConfig{Exporter: "stdout", SampleRatio: 0.5}},
+		{name: "unknown exporter", cfg: config.TracingConfig{Exporter: "zipkin"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewTracerProvider(context.Background(), "test", &tt.cfg, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTracerProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if provider != nil {
+				_ = provider.Shutdown(context.Background())
+			}
+		})
+	}
+}
+
+func TestNewTracerProvider_StdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	provider, err := NewTracerProvider(context.Background(), "test", &config.TracingConfig{Exporter: "stdout"}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := provider.Tracer(instrumentationName).Start(context.Background(), "ProjectUsecase.CreateProject")
+	span.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"Name":"ProjectUsecase.CreateProject"`) {
+		t.Errorf("exported spans = %s, want the ended span", buf.String())
+	}
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+		wantEvents int
+	}{
+		{name: "success", err: nil, wantStatus: codes.Unset},
+		{name: "error", err: errors.New("boom"), wantStatus: codes.Error, wantEvents: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			func() (err error) {
+				_, span := provider.Tracer(instrumentationName).Start(context.Background(), "CardUsecase.CreateCard")
+				defer End(span, &err)
+				return tt.err
+			}()
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("ended spans = %d, want 1", len(spans))
+			}
+			if spans[0].Status().Code != tt.wantStatus || len(spans[0].Events()) != tt.wantEvents {
+				t.Errorf("span status = %v, events = %d, want %v and %d", spans[0].Status().Code, len(spans[0].Events()), tt.wantStatus, tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/internal/tracing"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+// AccountUsecase handles the mailed flows of an account: email verification and password reset.
+type AccountUsecase interface {
+	RequestEmailVerification(ctx context.Context, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	// RequestPasswordReset mails a reset link; unknown emails are silently ignored so the endpoint
+	// cannot be used to discover accounts. The mailer must be asynchronous (service.MailQueue),
+	// otherwise the response time still tells whether a mail was sent.
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type accountUsecase struct {
+	db                 *database.DB
+	accountConfig      *config.AccountConfig
+	actionTokenService service.ActionTokenService
+	mailer             service.Mailer
+	userRepo           repository.UserRepository
+	actionTokenRepo    repository.UserActionTokenRepository
+	refreshTokenRepo   repository.RefreshTokenRepository
+	patRepo            repository.PersonalAccessTokenRepository
+}
+
+func NewAccountUsecase(db *database.DB, accountConfig *config.AccountConfig, actionTokenService service.ActionTokenService,
+	mailer service.Mailer, userRepository repository.UserRepository, actionTokenRepository repository.UserActionTokenRepository,
+	refreshTokenRepository repository.RefreshTokenRepository, personalAccessTokenRepository repository.PersonalAccessTokenRepository) AccountUsecase {
+	return &accountUsecase{
+		db:                 db,
+		accountConfig:      accountConfig,
+		actionTokenService: actionTokenService,
+		mailer:             mailer,
+		userRepo:           userRepository,
+		actionTokenRepo:    actionTokenRepository,
+		refreshTokenRepo:   refreshTokenRepository,
+		patRepo:            personalAccessTokenRepository,
+	}
+}
+
+func (a *accountUsecase) RequestEmailVerification(ctx context.Context, userID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountUsecase.RequestEmailVerification")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "account", "RequestEmailVerification")
+
+	var (
+		user  *model.User
+		token string
+	)
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		user, err = a.userRepo.GetByID(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt != nil {
+			return apperror.Conflict("Email is already verified")
+		}
+
+		token, err = a.issueActionToken(ctx, tx, user.ID, model.ActionTokenPurposeEmailVerification,
+			time.Duration(a.accountConfig.EmailVerificationExpirationInSecond)*time.Second)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.mailer.Send(ctx, service.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nIf you did not create an account, you can ignore this email.",
+			user.Name, fmt.Sprintf(a.accountConfig.EmailVerificationURL, token)),
+	})
+}
+
+func (a *accountUsecase) VerifyEmail(ctx context.Context, token string) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountUsecase.VerifyEmail")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "account", "VerifyEmail")
+
+	claims, err := a.actionTokenService.Validate(token, model.ActionTokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	return a.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		err := a.consumeActionToken(ctx, tx, claims)
+		if err != nil {
+			return err
+		}
+
+		return a.userRepo.MarkEmailVerified(ctx, tx, claims.UserID)
+	})
+}
+
+func (a *accountUsecase) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountUsecase.RequestPasswordReset")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "account", "RequestPasswordReset")
+
+	var (
+		user  *model.User
+		token string
+	)
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		user, err = a.userRepo.GetByEmail(ctx, tx, email)
+		if errors.Is(err, utils.ErrNotFound) {
+			user = nil
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		token, err = a.issueActionToken(ctx, tx, user.ID, model.ActionTokenPurposePasswordReset,
+			time.Duration(a.accountConfig.PasswordResetExpirationInSecond)*time.Second)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	return a.mailer.Send(ctx, service.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nIf you did not request this, you can ignore this email.",
+			user.Name, fmt.Sprintf(a.accountConfig.PasswordResetURL, token)),
+	})
+}
+
+func (a *accountUsecase) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountUsecase.ResetPassword")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "account", "ResetPassword")
+
+	if len(newPassword) < minPasswordLength {
+		return apperror.Validation("Password is too short", apperror.FieldError{
+			Field:   "password",
+			Message: fmt.Sprintf("must be at least %d characters", minPasswordLength),
+		})
+	}
+
+	claims, err := a.actionTokenService.Validate(token, model.ActionTokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return a.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		err := a.consumeActionToken(ctx, tx, claims)
+		if err != nil {
+			return err
+		}
+
+		err = a.userRepo.UpdatePassword(ctx, tx, claims.UserID, string(hashed))
+		if err != nil {
+			return err
+		}
+
+		// sesi lama tidak boleh bertahan setelah password diganti: refresh token, personal access
+		// token dan access token yang masih berlaku
+		err = a.refreshTokenRepo.RevokeAllByUserID(ctx, tx, claims.UserID)
+		if err != nil {
+			return err
+		}
+		err = a.patRepo.RevokeAllByUserID(ctx, tx, claims.UserID)
+		if err != nil {
+			return err
+		}
+		return a.userRepo.InvalidateTokens(ctx, tx, claims.UserID, time.Now())
+	})
+}
+
+func (a *accountUsecase) issueActionToken(ctx context.Context, tx *sql.Tx, userID int64, purpose string, ttl time.Duration) (string, error) {
+	err := a.actionTokenRepo.InvalidateByUserID(ctx, tx, userID, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	stored := &model.UserActionToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	err = a.actionTokenRepo.Create(ctx, tx, stored)
+	if err != nil {
+		return "", err
+	}
+
+	return a.actionTokenService.Generate(purpose, userID, stored.ID, stored.ExpiresAt)
+}
+
+func (a *accountUsecase) consumeActionToken(ctx context.Context, tx *sql.Tx, claims *service.ActionTokenClaims) error {
+	err := a.actionTokenRepo.Consume(ctx, tx, claims.ID, claims.UserID, claims.Purpose)
+	if errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("%w: token has already been used", utils.ErrInvalidToken)
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+type accountTestSetup struct {
+	usecase       *accountUsecase
+	users         *fakeUserRepository
+	refreshTokens *fakeRefreshTokenRepository
+	tokens        *fakePersonalAccessTokenRepository
+	mailer        *fakeMailer
+}
+
+func newAccountTestSetup(t *testing.T) *accountTestSetup {
+	t.Helper()
+
+	actionTokenService, err := service.NewActionTokenService("account-token-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, _ := databasetest.New()
+	s := &accountTestSetup{
+		users:         newFakeUserRepository(&model.User{ID: testUser.ID, Name: testUser.Name, Email: testUser.Email}),
+		refreshTokens: &fakeRefreshTokenRepository{},
+		tokens:        &fakePersonalAccessTokenRepository{},
+		mailer:        &fakeMailer{},
+	}
+	s.usecase = NewAccountUsecase(db, &config.AccountConfig{
+		PasswordResetURL:                "https://app.example.com/reset-password?token=%s",
+		PasswordResetExpirationInSecond: 3600,
+	}, actionTokenService, s.mailer, s.users, &fakeUserActionTokenRepository{}, s.refreshTokens, s.tokens).(*accountUsecase)
+	return s
+}
+
+// resetToken returns the token from the link of the last mailed message.
+func (s *accountTestSetup) resetToken(t *testing.T) string {
+	t.Helper()
+	if len(s.mailer.messages) == 0 {
+		t.Fatal("no mail sent")
+	}
+	body := s.mailer.messages[len(s.mailer.messages)-1].Body
+	_, after, ok := strings.Cut(body, "?token=")
+	if !ok {
+		t.Fatalf("no reset link in %q", body)
+	}
+	token, _, _ := strings.Cut(after, "\n")
+	return token
+}
+
+func TestAccountUsecase_RequestPasswordReset(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		wantMails int
+	}{
+		{name: "known email", email: testUser.Email, wantMails: 1},
+		{name: "unknown email", email: "nobody@example.com", wantMails: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAccountTestSetup(t)
+
+			// hasilnya harus sama persis supaya endpoint tidak bisa dipakai mencari akun
+			if err := s.usecase.RequestPasswordReset(context.Background(), tt.email); err != nil {
+				t.Fatalf("RequestPasswordReset() error = %v", err)
+			}
+			if len(s.mailer.messages) != tt.wantMails {
+				t.Errorf("mails = %d, want %d", len(s.mailer.messages), tt.wantMails)
+			}
+		})
+	}
+}
+
+func TestAccountUsecase_ResetPassword(t *testing.T) {
+	ctx := context.Background()
+	s := newAccountTestSetup(t)
+
+	if err := s.refreshTokens.Create(ctx, nil, &model.RefreshToken{UserID: testUser.ID, FamilyID: "family-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.tokens.Create(ctx, nil, &model.PersonalAccessToken{UserID: testUser.ID, TokenHash: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.usecase.RequestPasswordReset(ctx, testUser.Email); err != nil {
+		t.Fatal(err)
+	}
+	token := s.resetToken(t)
+
+	if err := s.usecase.ResetPassword(ctx, token, "new-password"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	if active := s.refreshTokens.active("family-1"); len(active) != 0 {
+		t.Errorf("active refresh tokens = %+v, want none", active)
+	}
+	if pat, _ := s.tokens.GetByHash(ctx, nil, "hash"); pat.RevokedAt == nil {
+		t.Error("personal access token is not revoked")
+	}
+	if _, ok := s.users.tokensInvalidatedAt[testUser.ID]; !ok {
+		t.Error("access tokens issued before the reset are not invalidated")
+	}
+
+	if err := s.usecase.ResetPassword(ctx, token, "another-password"); !errors.Is(err, utils.ErrInvalidToken) {
+		t.Errorf("ResetPassword() with a used token error = %v, want %v", err, utils.ErrInvalidToken)
+	}
+}
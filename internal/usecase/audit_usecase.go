@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/tracing"
+)
+
+type AuditUsecase interface {
+	Record(ctx context.Context, entry *model.AuditLog) error
+	// ConfigReloaded is a config.Subscriber that audits every reload of the runtime settings.
+	ConfigReloaded(runtime config.RuntimeConfig, changes []config.Change)
+}
+
+type auditUsecase struct {
+	db           *database.DB
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditUsecase(db *database.DB, auditLogRepository repository.AuditLogRepository) AuditUsecase {
+	return &auditUsecase{
+		db:           db,
+		auditLogRepo: auditLogRepository,
+	}
+}
+
+func (a *auditUsecase) Record(ctx context.Context, entry *model.AuditLog) (err error) {
+	ctx, span := tracing.Start(ctx, "AuditUsecase.Record")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "audit", "Record")
+
+	return a.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return a.auditLogRepo.Create(ctx, tx, entry)
+	})
+}
+
+// ConfigReloaded writes the audit log in the background, so a slow database does not hold up the
+// file watcher and the subscribers after it.
+func (a *auditUsecase) ConfigReloaded(_ config.RuntimeConfig, changes []config.Change) {
+	details := make([]string, len(changes))
+	for i, change := range changes {
+		details[i] = change.String()
+	}
+	entry := &model.AuditLog{
+		Action: model.AuditActionConfigReloaded,
+		Detail: strings.Join(details, "; "),
+	}
+
+	go func() {
+		// dipanggil dari watcher file, bukan dari request, jadi butuh context sendiri
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := a.Record(ctx, entry); err != nil {
+			slog.ErrorContext(ctx, "failed to write audit log", slog.String("action", entry.Action), slog.Any("error", err))
+		}
+	}()
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/model"
+)
+
+func TestAuditUsecase_ConfigReloaded(t *testing.T) {
+	db, _ := databasetest.New()
+	auditLogs := &fakeAuditLogRepository{}
+	a := NewAuditUsecase(db, auditLogs)
+
+	a.ConfigReloaded(config.RuntimeConfig{}, []config.Change{
+		{Key: "Application.Log.Level", Old: "info", New: "debug"},
+		{Key: "Features.disable_registration", Old: "false", New: "true"},
+	})
+
+	// audit log ditulis di background
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		auditLogs.mu.Lock()
+		entries := append([]*model.AuditLog(nil), auditLogs.entries...)
+		auditLogs.mu.Unlock()
+
+		if len(entries) == 1 {
+			want := `Application.Log.Level: "info" -> "debug"; Features.disable_registration: "false" -> "true"`
+			if entries[0].Action != model.AuditActionConfigReloaded || entries[0].Detail != want {
+				t.Errorf("audit log = %+v, want the config reload with %q", entries[0], want)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("audit logs = %+v, want one config reload entry", entries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,641 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/internal/tracing"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthUsecase interface {
+	Register(ctx context.Context, user *model.User) (*model.TokenPair, error)
+	// Login returns an MFAChallenge instead of a token pair when the account has 2FA enabled.
+	// Failed attempts are throttled per account and per clientIP, see service.LoginThrottle.
+	Login(ctx context.Context, email, password, clientIP string) (*model.TokenPair, *model.MFAChallenge, error)
+	CompleteMFALogin(ctx context.Context, mfaToken, code, clientIP string) (*model.TokenPair, error)
+	// LoginWithExternalIdentity signs in the user linked to an identity verified by an external provider,
+	// linking or provisioning a local user on first login. Like Login it returns an MFAChallenge
+	// when the account has 2FA enabled.
+	LoginWithExternalIdentity(ctx context.Context, identity *model.ExternalIdentity, autoProvision bool) (*model.TokenPair, *model.MFAChallenge, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string, principal *model.Principal) error
+	// IsTokenRevoked reports whether the access token was revoked by logout or by a password reset
+	// of its user after it was issued.
+	IsTokenRevoked(ctx context.Context, userID int64, tokenID string, issuedAt time.Time) (bool, error)
+	// RunRevokedTokenCleanup removes the revoked access tokens that have expired until ctx is done.
+	RunRevokedTokenCleanup(ctx context.Context)
+}
+
+// dummyPasswordHash is checked for unknown emails so they take as long as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hashed
+})
+
+// revokedTokenCleanupInterval is how long expired tokens may stay on the denylist; they are
+// rejected by their exp claim anyway, so it only bounds the size of the table.
+const revokedTokenCleanupInterval = time.Hour
+
+type authUsecase struct {
+	db                 *database.DB
+	jwtConfig          *config.JwtConfig
+	jwtService         service.JWTService
+	userRepo           repository.UserRepository
+	refreshTokenRepo   repository.RefreshTokenRepository
+	revokedTokenRepo   repository.RevokedTokenRepository
+	identityRepo       repository.UserIdentityRepository
+	mfaConfig          *config.MfaConfig
+	actionTokenService service.ActionTokenService
+	actionTokenRepo    repository.UserActionTokenRepository
+	mfaRepo            repository.UserMFARepository
+	recoveryCodeRepo   repository.RecoveryCodeRepository
+	loginThrottle      service.LoginThrottle
+	audit              AuditUsecase
+}
+
+func NewAuthUsecase(db *database.DB, jwtConfig *config.JwtConfig, jwtService service.JWTService,
+	userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository,
+	revokedTokenRepository repository.RevokedTokenRepository, identityRepository repository.UserIdentityRepository,
+	mfaConfig *config.MfaConfig, actionTokenService service.ActionTokenService, actionTokenRepository repository.UserActionTokenRepository,
+	mfaRepository repository.UserMFARepository,
+	recoveryCodeRepository repository.RecoveryCodeRepository, loginThrottle service.LoginThrottle,
+	auditUsecase AuditUsecase) AuthUsecase {
+	return &authUsecase{
+		db:                 db,
+		jwtConfig:          jwtConfig,
+		jwtService:         jwtService,
+		userRepo:           userRepository,
+		refreshTokenRepo:   refreshTokenRepository,
+		revokedTokenRepo:   revokedTokenRepository,
+		identityRepo:       identityRepository,
+		mfaConfig:          mfaConfig,
+		actionTokenService: actionTokenService,
+		actionTokenRepo:    actionTokenRepository,
+		mfaRepo:            mfaRepository,
+		recoveryCodeRepo:   recoveryCodeRepository,
+		loginThrottle:      loginThrottle,
+		audit:              auditUsecase,
+	}
+}
+
+func (a *authUsecase) Register(ctx context.Context, user *model.User) (_ *model.TokenPair, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Register")
+	defer tracing.End(span, &err)
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = string(hashed)
+
+	// bcrypt sengaja tidak ikut dihitung sebagai waktu transaksi
+	ctx = metrics.WithTransaction(ctx, "auth", "Register")
+
+	var tokenPair *model.TokenPair
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		err = a.userRepo.Create(ctx, tx, user)
+		if err != nil {
+			return err
+		}
+
+		tokenPair, _, err = a.issueTokenPair(ctx, tx, user, uuid.NewString(), false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.UsersRegistered.Inc()
+
+	return tokenPair, nil
+}
+
+func (a *authUsecase) Login(ctx context.Context, email, password, clientIP string) (_ *model.TokenPair, _ *model.MFAChallenge, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Login")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "auth", "Login")
+
+	subjects := []service.LoginSubject{service.AccountSubject(email), service.IPSubject(clientIP)}
+	if err := a.loginThrottle.Begin(ctx, subjects...); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		tokenPair *model.TokenPair
+		challenge *model.MFAChallenge
+		userID    *int64
+	)
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		user, err := a.userRepo.GetByEmail(ctx, tx, email)
+		if errors.Is(err, utils.ErrNotFound) {
+			// email yang tidak terdaftar tetap dihitung dan tetap menjalankan bcrypt, agar lockout
+			// maupun waktu respons tidak membocorkan akun mana yang ada
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			return utils.ErrInvalidCredentials
+		}
+		if err != nil {
+			return err
+		}
+		userID = &user.ID
+
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+		if err != nil {
+			return utils.ErrInvalidCredentials
+		}
+
+		tokenPair, challenge, err = a.completeFirstFactor(ctx, tx, user)
+		return err
+	})
+	switch {
+	case errors.Is(err, utils.ErrInvalidCredentials):
+		a.recordLoginFailure(ctx, userID, clientIP, subjects...)
+	case err != nil:
+		a.releaseLoginAttempt(ctx, subjects...)
+	case challenge != nil:
+		// password saja belum cukup: percobaan untuk akun tetap terhitung sampai MFA selesai
+		a.releaseLoginAttempt(ctx, service.IPSubject(clientIP))
+	default:
+		a.succeedLoginAttempt(ctx, subjects...)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokenPair, challenge, nil
+}
+
+func (a *authUsecase) CompleteMFALogin(ctx context.Context, mfaToken, code, clientIP string) (_ *model.TokenPair, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.CompleteMFALogin")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "auth", "CompleteMFALogin")
+
+	claims, err := a.actionTokenService.Validate(mfaToken, model.ActionTokenPurposeMFALogin)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.getUser(ctx, claims.UserID)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, utils.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// kode TOTP hanya 6 digit, jadi percobaan yang gagal ikut dibatasi seperti password
+	subjects := []service.LoginSubject{service.AccountSubject(user.Email), service.IPSubject(clientIP)}
+	err = a.loginThrottle.Begin(ctx, subjects...)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenPair *model.TokenPair
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		// token yang sudah dipakai atau dibatalkan tidak boleh dipakai menebak kode; lock membuat
+		// percobaan paralel dengan token yang sama antre
+		err = a.actionTokenRepo.LockUnused(ctx, tx, claims.ID, claims.UserID, claims.Purpose)
+		if errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("%w: token has already been used", utils.ErrInvalidToken)
+		}
+		if err != nil {
+			return err
+		}
+
+		mfa, err := a.mfaRepo.GetByUserIDForUpdate(ctx, tx, claims.UserID)
+		if errors.Is(err, utils.ErrNotFound) || (err == nil && mfa.EnabledAt == nil) {
+			return utils.ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		err = verifySecondFactor(ctx, tx, a.mfaRepo, a.recoveryCodeRepo, mfa, code)
+		if err != nil {
+			return err
+		}
+
+		err = a.actionTokenRepo.Consume(ctx, tx, claims.ID, claims.UserID, claims.Purpose)
+		if err != nil {
+			return err
+		}
+
+		tokenPair, _, err = a.issueTokenPair(ctx, tx, user, uuid.NewString(), true)
+		return err
+	})
+	switch {
+	case errors.Is(err, utils.ErrInvalidCredentials):
+		a.recordMFAFailure(ctx, claims.ID, user.ID, clientIP, subjects...)
+	case err != nil:
+		a.releaseLoginAttempt(ctx, subjects...)
+	default:
+		a.succeedLoginAttempt(ctx, subjects...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenPair, nil
+}
+
+// getUser loads a user outside of a write transaction, from the primary so a just changed or
+// disabled account is seen.
+func (a *authUsecase) getUser(ctx context.Context, id int64) (*model.User, error) {
+	tx, err := a.db.BeginTx(database.WithPrimary(ctx), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return a.userRepo.GetByID(ctx, tx, id)
+}
+
+func (a *authUsecase) LoginWithExternalIdentity(ctx context.Context, identity *model.ExternalIdentity, autoProvision bool) (_ *model.TokenPair, _ *model.MFAChallenge, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.LoginWithExternalIdentity")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "auth", "LoginWithExternalIdentity")
+
+	var (
+		tokenPair *model.TokenPair
+		challenge *model.MFAChallenge
+	)
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		var user *model.User
+
+		linked, err := a.identityRepo.GetByProviderSubject(ctx, tx, identity.Provider, identity.Subject)
+		switch {
+		case err == nil:
+			user, err = a.userRepo.GetByID(ctx, tx, linked.UserID)
+			if err != nil {
+				return err
+			}
+			err = a.identityRepo.UpdateLastLogin(ctx, tx, linked.ID, identity.Email)
+			if err != nil {
+				return err
+			}
+
+		case errors.Is(err, utils.ErrNotFound):
+			user, err = a.linkExternalIdentity(ctx, tx, identity, autoProvision)
+			if err != nil {
+				return err
+			}
+
+		default:
+			return err
+		}
+
+		// provider tidak menggantikan faktor kedua yang diaktifkan user di sini
+		tokenPair, challenge, err = a.completeFirstFactor(ctx, tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokenPair, challenge, nil
+}
+
+// completeFirstFactor finishes a login whose first factor was verified: it issues a token pair,
+// or an MFAChallenge when the user has 2FA enabled.
+func (a *authUsecase) completeFirstFactor(ctx context.Context, tx *sql.Tx, user *model.User) (*model.TokenPair, *model.MFAChallenge, error) {
+	mfa, err := a.mfaRepo.GetByUserIDForUpdate(ctx, tx, user.ID)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return nil, nil, err
+	}
+	if err == nil && mfa.EnabledAt != nil {
+		challenge, err := a.mfaChallenge(ctx, tx, user.ID)
+		return nil, challenge, err
+	}
+
+	tokenPair, _, err := a.issueTokenPair(ctx, tx, user, uuid.NewString(), false)
+	return tokenPair, nil, err
+}
+
+// linkExternalIdentity attaches a new identity to the local user with the same (provider verified)
+// email, or provisions a new user when allowed. Both emails must be verified.
+func (a *authUsecase) linkExternalIdentity(ctx context.Context, tx *sql.Tx, identity *model.ExternalIdentity, autoProvision bool) (*model.User, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, fmt.Errorf("%w: identity provider did not return a verified email", utils.ErrInvalidCredentials)
+	}
+
+	user, err := a.userRepo.GetByEmail(ctx, tx, identity.Email)
+	if errors.Is(err, utils.ErrNotFound) {
+		if !autoProvision {
+			return nil, fmt.Errorf("%w: no local account for %s", utils.ErrInvalidCredentials, identity.Email)
+		}
+
+		// user SSO tidak punya password lokal, isi dengan hash dari nilai acak
+		randomPassword, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		name := identity.Name
+		if name == "" {
+			name = identity.Email
+		}
+		user = &model.User{Name: name, Email: identity.Email, Password: string(hashed)}
+		err = a.userRepo.Create(ctx, tx, user)
+		if err != nil {
+			return nil, err
+		}
+		// email sudah diverifikasi oleh provider
+		err = a.userRepo.MarkEmailVerified(ctx, tx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else if user.EmailVerifiedAt == nil {
+		// siapa pun bisa mendaftar dengan email orang lain; tanpa verifikasi, akun itu tidak boleh diambil alih lewat SSO
+		return nil, fmt.Errorf("%w: verify the email of the local account before signing in with %s", utils.ErrInvalidCredentials, identity.Provider)
+	}
+
+	err = a.identityRepo.Create(ctx, tx, &model.UserIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Refresh rotates the presented refresh token. Presenting a token that was already rotated
+// means it leaked, so the whole family is revoked and the caller has to log in again.
+func (a *authUsecase) Refresh(ctx context.Context, refreshToken string) (_ *model.TokenPair, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Refresh")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "auth", "Refresh")
+
+	var (
+		tokenPair *model.TokenPair
+		reused    bool
+	)
+	err = a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		current, err := a.refreshTokenRepo.GetByHashForUpdate(ctx, tx, utils.HashToken(refreshToken))
+		if errors.Is(err, utils.ErrNotFound) {
+			return utils.ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		// pencabutan family harus tetap di-commit, jadi error-nya baru dikembalikan setelah transaksi
+		reused = current.RevokedAt != nil
+		if reused {
+			return a.refreshTokenRepo.RevokeFamily(ctx, tx, current.FamilyID)
+		}
+
+		if time.Now().After(current.ExpiresAt) {
+			return utils.ErrInvalidToken
+		}
+
+		user, err := a.userRepo.GetByID(ctx, tx, current.UserID)
+		if errors.Is(err, utils.ErrNotFound) {
+			return utils.ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		var next *model.RefreshToken
+		tokenPair, next, err = a.issueTokenPair(ctx, tx, user, current.FamilyID, current.MFAVerified)
+		if err != nil {
+			return err
+		}
+
+		return a.refreshTokenRepo.Revoke(ctx, tx, current.ID, &next.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, utils.ErrTokenReused
+	}
+
+	return tokenPair, nil
+}
+
+func (a *authUsecase) Logout(ctx context.Context, refreshToken string, principal *model.Principal) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Logout")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "auth", "Logout")
+
+	return a.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		// personal access token dicabut lewat endpoint token sendiri, bukan lewat denylist
+		if principal.AuthMethod == model.AuthMethodJWT {
+			err := a.revokedTokenRepo.Create(ctx, tx, &model.RevokedToken{
+				JTI:       principal.TokenID,
+				UserID:    principal.UserID,
+				ExpiresAt: principal.TokenExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if refreshToken == "" {
+			return nil
+		}
+		current, err := a.refreshTokenRepo.GetByHashForUpdate(ctx, tx, utils.HashToken(refreshToken))
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// tidak boleh mencabut token milik user lain
+		if current.UserID != principal.UserID {
+			return nil
+		}
+		return a.refreshTokenRepo.RevokeFamily(ctx, tx, current.FamilyID)
+	})
+}
+
+func (a *authUsecase) IsTokenRevoked(ctx context.Context, userID int64, tokenID string, issuedAt time.Time) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.IsTokenRevoked")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "auth", "IsTokenRevoked")
+
+	// revocation harus langsung berlaku, replica bisa tertinggal
+	var revoked bool
+	err = a.db.RunInTx(database.WithPrimary(ctx), &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		revoked, err = a.revokedTokenRepo.IsRevoked(ctx, tx, tokenID, userID, issuedAt)
+		return err
+	})
+	return revoked, err
+}
+
+func (a *authUsecase) RunRevokedTokenCleanup(ctx context.Context) {
+	ticker := time.NewTicker(revokedTokenCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		a.deleteExpiredRevokedTokens(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteExpiredRevokedTokens removes the denylist entries whose token expired, at most one
+// access-token lifetime after it was revoked.
+func (a *authUsecase) deleteExpiredRevokedTokens(ctx context.Context) {
+	ctx = metrics.WithTransaction(ctx, "auth", "DeleteExpiredRevokedTokens")
+
+	var deleted int64
+	err := a.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		deleted, err = a.revokedTokenRepo.DeleteExpired(ctx, tx, time.Now())
+		return err
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete expired revoked tokens", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "expired revoked tokens deleted", slog.Int64("count", deleted))
+	}
+}
+
+// issueTokenPair starts or continues the session familyID; mfaVerified marks a session whose login
+// was completed with a second factor.
+func (a *authUsecase) issueTokenPair(ctx context.Context, tx *sql.Tx, user *model.User, familyID string, mfaVerified bool) (*model.TokenPair, *model.RefreshToken, error) {
+	var amr []string
+	if mfaVerified {
+		amr = []string{model.AMRMFA}
+	}
+	accessToken, err := a.jwtService.GenerateToken(user.ID, user.Email, amr, user.Roles...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored := &model.RefreshToken{
+		UserID:      user.ID,
+		FamilyID:    familyID,
+		TokenHash:   utils.HashToken(refreshToken),
+		ExpiresAt:   time.Now().Add(time.Duration(a.jwtConfig.RefreshExpirationInSecond) * time.Second),
+		MFAVerified: mfaVerified,
+	}
+	err = a.refreshTokenRepo.Create(ctx, tx, stored)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(a.jwtConfig.ExpirationInSecond),
+	}, stored, nil
+}
+
+func (a *authUsecase) mfaChallenge(ctx context.Context, tx *sql.Tx, userID int64) (*model.MFAChallenge, error) {
+	ttl := time.Duration(a.mfaConfig.ChallengeExpirationInSecond) * time.Second
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	// disimpan supaya token hanya bisa dipakai sekali dan percobaan kodenya bisa dihitung
+	stored := &model.UserActionToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Purpose:   model.ActionTokenPurposeMFALogin,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	err := a.actionTokenRepo.Create(ctx, tx, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaToken, err := a.actionTokenService.Generate(stored.Purpose, userID, stored.ID, stored.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MFAChallenge{
+		MFARequired: true,
+		MFAToken:    mfaToken,
+		ExpiresIn:   int64(ttl.Seconds()),
+	}, nil
+}
+
+// recordLoginFailure counts a failed attempt and audits the subjects that got locked out. Errors are
+// only logged so they never change the response of the login itself.
+func (a *authUsecase) recordLoginFailure(ctx context.Context, userID *int64, clientIP string, subjects ...service.LoginSubject) {
+	lockedOut, err := a.loginThrottle.Fail(ctx, subjects...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record login failure", slog.Any("error", err))
+	}
+
+	for _, subject := range lockedOut {
+		entry := &model.AuditLog{
+			Action:    model.AuditActionAccountLocked,
+			IPAddress: clientIP,
+			Detail:    fmt.Sprintf("too many failed logins for %s", subject.Value),
+		}
+		if subject.Kind == service.LoginSubjectAccount {
+			entry.UserID = userID
+		} else {
+			entry.Action = model.AuditActionIPLocked
+		}
+
+		if err := a.audit.Record(ctx, entry); err != nil {
+			slog.ErrorContext(ctx, "failed to write audit log", slog.String("action", entry.Action), slog.Any("error", err))
+		}
+	}
+}
+
+// recordMFAFailure voids the MFA token after MaxChallengeAttempts wrong codes, on top of the
+// throttling of the account and client IP.
+func (a *authUsecase) recordMFAFailure(ctx context.Context, tokenID string, userID int64, clientIP string, subjects ...service.LoginSubject) {
+	err := a.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return a.actionTokenRepo.RecordFailure(ctx, tx, tokenID, a.mfaConfig.MaxChallengeAttempts)
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record mfa failure", slog.Any("error", err))
+	}
+
+	a.recordLoginFailure(ctx, &userID, clientIP, subjects...)
+}
+
+// succeedLoginAttempt and releaseLoginAttempt run after the login has been decided, so like
+// recordLoginFailure their errors are only logged.
+func (a *authUsecase) succeedLoginAttempt(ctx context.Context, subjects ...service.LoginSubject) {
+	if err := a.loginThrottle.Succeed(ctx, subjects...); err != nil {
+		slog.ErrorContext(ctx, "failed to reset login throttle", slog.Any("error", err))
+	}
+}
+
+func (a *authUsecase) releaseLoginAttempt(ctx context.Context, subjects ...service.LoginSubject) {
+	if err := a.loginThrottle.Release(ctx, subjects...); err != nil {
+		slog.ErrorContext(ctx, "failed to release login attempt", slog.Any("error", err))
+	}
+}
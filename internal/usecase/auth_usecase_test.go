@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testUser = &model.User{ID: 1, Name: "Test", Email: "test@example.com"}
+
+const (
+	mfaTestPassword     = "correct-password"
+	mfaTestRecoveryCode = "abcde-fghij"
+)
+
+type authTestSetup struct {
+	usecase         *authUsecase
+	refreshTokens   *fakeRefreshTokenRepository
+	revokedTokens   *fakeRevokedTokenRepository
+	actionTokens    *fakeUserActionTokenRepository
+	auditLogs       *fakeAuditLogRepository
+	recoveryCodes   *fakeRecoveryCodeRepository
+	loginProtection *config.LoginProtectionConfig
+	// mfaUser logs in with a password and a second factor; mfaTestRecoveryCode is its only recovery code
+	mfaUser *model.User
+}
+
+func newAuthTestSetup(t *testing.T) *authTestSetup {
+	t.Helper()
+
+	jwtConfig := &config.JwtConfig{SecretKey: "jwt_key", ExpirationInSecond: 3600, RefreshExpirationInSecond: 86400}
+	jwtService, err := service.NewJwtService(jwtConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionTokenService, err := service.NewActionTokenService("account-token-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(mfaTestPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabledAt := time.Now()
+	mfaUser := &model.User{ID: 2, Name: "MFA", Email: "mfa@example.com", Password: string(hashed), Roles: []string{model.RoleAdmin}}
+
+	db, _ := databasetest.New()
+	s := &authTestSetup{
+		refreshTokens: &fakeRefreshTokenRepository{},
+		revokedTokens: &fakeRevokedTokenRepository{},
+		actionTokens:  &fakeUserActionTokenRepository{},
+		auditLogs:     &fakeAuditLogRepository{},
+		recoveryCodes: &fakeRecoveryCodeRepository{codes: map[int64][]string{
+			mfaUser.ID: {utils.HashToken(normalizeRecoveryCode(mfaTestRecoveryCode))},
+		}},
+		loginProtection: &config.LoginProtectionConfig{FreeAttempts: 100, AccountMaxAttempts: 100, IPMaxAttempts: 100},
+		mfaUser:         mfaUser,
+	}
+	s.usecase = &authUsecase{
+		db:                 db,
+		jwtConfig:          jwtConfig,
+		jwtService:         jwtService,
+		userRepo:           newFakeUserRepository(testUser, mfaUser),
+		refreshTokenRepo:   s.refreshTokens,
+		revokedTokenRepo:   s.revokedTokens,
+		mfaConfig:          &config.MfaConfig{ChallengeExpirationInSecond: 300, MaxChallengeAttempts: 3},
+		actionTokenService: actionTokenService,
+		actionTokenRepo:    s.actionTokens,
+		mfaRepo: &fakeUserMFARepository{mfas: map[int64]*model.UserMFA{
+			mfaUser.ID: {UserID: mfaUser.ID, Secret: secret, EnabledAt: &enabledAt},
+		}},
+		recoveryCodeRepo: s.recoveryCodes,
+		loginThrottle:    service.NewLoginThrottle(s.loginProtection, service.NewMemoryAttemptStore()),
+		audit:            NewAuditUsecase(db, s.auditLogs),
+	}
+	return s
+}
+
+// mfaChallenge logs mfaUser in with its password and returns the MFA token of the challenge.
+func (s *authTestSetup) mfaChallenge(t *testing.T) string {
+	t.Helper()
+	tokenPair, challenge, err := s.usecase.Login(context.Background(), s.mfaUser.Email, mfaTestPassword, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokenPair != nil || challenge == nil {
+		t.Fatalf("Login() = %+v, %+v, want an MFA challenge", tokenPair, challenge)
+	}
+	return challenge.MFAToken
+}
+
+// login issues a first token pair the way Login and Register do.
+func (s *authTestSetup) login(t *testing.T) *model.TokenPair {
+	t.Helper()
+	tokenPair, _, err := s.usecase.issueTokenPair(context.Background(), nil, testUser, "family-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenPair
+}
+
+func TestAuthUsecase_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		first := s.login(t)
+
+		second, err := s.usecase.Refresh(ctx, first.RefreshToken)
+		if err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		if second.RefreshToken == first.RefreshToken || second.AccessToken == "" {
+			t.Errorf("Refresh() = %+v, want a new token pair", second)
+		}
+
+		active := s.refreshTokens.active("family-1")
+		if len(active) != 1 || active[0].TokenHash != utils.HashToken(second.RefreshToken) {
+			t.Errorf("active tokens = %+v, want only the rotated token", active)
+		}
+		old, _ := s.refreshTokens.GetByHashForUpdate(ctx, nil, utils.HashToken(first.RefreshToken))
+		if old.ReplacedBy == nil || *old.ReplacedBy != active[0].ID {
+			t.Errorf("old token replaced_by = %v, want %v", old.ReplacedBy, active[0].ID)
+		}
+	})
+
+	t.Run("reuse revokes the whole family", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		first := s.login(t)
+
+		second, err := s.usecase.Refresh(ctx, first.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// token lama dipakai lagi, berarti bocor
+		if _, err := s.usecase.Refresh(ctx, first.RefreshToken); !errors.Is(err, utils.ErrTokenReused) {
+			t.Fatalf("Refresh() with a rotated token error = %v, want %v", err, utils.ErrTokenReused)
+		}
+		if active := s.refreshTokens.active("family-1"); len(active) != 0 {
+			t.Errorf("active tokens = %+v, want the family revoked", active)
+		}
+		if _, err := s.usecase.Refresh(ctx, second.RefreshToken); !errors.Is(err, utils.ErrTokenReused) {
+			t.Errorf("Refresh() with the latest token after reuse error = %v, want %v", err, utils.ErrTokenReused)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		if _, err := s.usecase.Refresh(ctx, "unknown"); !errors.Is(err, utils.ErrInvalidToken) {
+			t.Errorf("Refresh() error = %v, want %v", err, utils.ErrInvalidToken)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		first := s.login(t)
+		s.refreshTokens.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+		if _, err := s.usecase.Refresh(ctx, first.RefreshToken); !errors.Is(err, utils.ErrInvalidToken) {
+			t.Errorf("Refresh() error = %v, want %v", err, utils.ErrInvalidToken)
+		}
+	})
+}
+
+func TestAuthUsecase_CompleteMFALogin(t *testing.T) {
+	ctx := context.Background()
+	const clientIP = "192.0.2.1"
+
+	t.Run("second factor marks the session and survives refresh", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+
+		tokenPair, err := s.usecase.CompleteMFALogin(ctx, s.mfaChallenge(t), mfaTestRecoveryCode, clientIP)
+		if err != nil {
+			t.Fatalf("CompleteMFALogin() error = %v", err)
+		}
+		refreshed, err := s.usecase.Refresh(ctx, tokenPair.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, accessToken := range []string{tokenPair.AccessToken, refreshed.AccessToken} {
+			claims, err := s.usecase.jwtService.ValidateToken(accessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			principal := &model.Principal{Roles: claims.Roles, AMR: claims.AMR}
+			if !principal.IsAdmin() {
+				t.Errorf("access token roles = %v, amr = %v, want an admin verified with mfa", claims.Roles, claims.AMR)
+			}
+		}
+	})
+
+	t.Run("token can only be used once", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		s.recoveryCodes.codes[s.mfaUser.ID] = append(s.recoveryCodes.codes[s.mfaUser.ID], utils.HashToken("klmnopqrst"))
+		mfaToken := s.mfaChallenge(t)
+
+		if _, err := s.usecase.CompleteMFALogin(ctx, mfaToken, mfaTestRecoveryCode, clientIP); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.usecase.CompleteMFALogin(ctx, mfaToken, "klmno-pqrst", clientIP); !errors.Is(err, utils.ErrInvalidToken) {
+			t.Errorf("CompleteMFALogin() with a used token error = %v, want %v", err, utils.ErrInvalidToken)
+		}
+	})
+
+	t.Run("wrong codes void the token", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		mfaToken := s.mfaChallenge(t)
+
+		for i := 0; i < s.usecase.mfaConfig.MaxChallengeAttempts; i++ {
+			if _, err := s.usecase.CompleteMFALogin(ctx, mfaToken, "000000", clientIP); !errors.Is(err, utils.ErrInvalidCredentials) {
+				t.Fatalf("attempt %d error = %v, want %v", i+1, err, utils.ErrInvalidCredentials)
+			}
+		}
+		// kode yang benar pun ditolak, user harus login ulang dengan password
+		if _, err := s.usecase.CompleteMFALogin(ctx, mfaToken, mfaTestRecoveryCode, clientIP); !errors.Is(err, utils.ErrInvalidToken) {
+			t.Errorf("CompleteMFALogin() after %d wrong codes error = %v, want %v", s.usecase.mfaConfig.MaxChallengeAttempts, err, utils.ErrInvalidToken)
+		}
+	})
+}
+
+func TestAuthUsecase_LoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	const clientIP = "192.0.2.1"
+
+	t.Run("wrong mfa codes lock the account", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		s.loginProtection.AccountMaxAttempts = 4
+
+		// login dengan password benar ikut terhitung, ditambah tiga kode yang salah
+		mfaToken := s.mfaChallenge(t)
+		for i := 0; i < s.usecase.mfaConfig.MaxChallengeAttempts; i++ {
+			if _, err := s.usecase.CompleteMFALogin(ctx, mfaToken, "000000", clientIP); !errors.Is(err, utils.ErrInvalidCredentials) {
+				t.Fatalf("attempt %d error = %v, want %v", i+1, err, utils.ErrInvalidCredentials)
+			}
+		}
+
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, mfaTestPassword, clientIP); !errors.Is(err, utils.ErrTooManyAttempts) {
+			t.Errorf("Login() error = %v, want %v", err, utils.ErrTooManyAttempts)
+		}
+		if len(s.auditLogs.entries) != 1 || s.auditLogs.entries[0].Action != model.AuditActionAccountLocked {
+			t.Errorf("audit logs = %+v, want the account lockout", s.auditLogs.entries)
+		}
+	})
+
+	t.Run("password alone does not reset the account", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		s.loginProtection.AccountMaxAttempts = 3
+
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, "wrong-password", clientIP); !errors.Is(err, utils.ErrInvalidCredentials) {
+			t.Fatal(err)
+		}
+		s.mfaChallenge(t)
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, "wrong-password", clientIP); !errors.Is(err, utils.ErrInvalidCredentials) {
+			t.Fatal(err)
+		}
+
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, mfaTestPassword, clientIP); !errors.Is(err, utils.ErrTooManyAttempts) {
+			t.Errorf("Login() error = %v, want %v", err, utils.ErrTooManyAttempts)
+		}
+	})
+
+	t.Run("completed mfa resets the account", func(t *testing.T) {
+		s := newAuthTestSetup(t)
+		s.loginProtection.AccountMaxAttempts = 3
+
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, "wrong-password", clientIP); !errors.Is(err, utils.ErrInvalidCredentials) {
+			t.Fatal(err)
+		}
+		if _, err := s.usecase.CompleteMFALogin(ctx, s.mfaChallenge(t), mfaTestRecoveryCode, clientIP); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, "wrong-password", clientIP); !errors.Is(err, utils.ErrInvalidCredentials) {
+			t.Fatal(err)
+		}
+		if _, _, err := s.usecase.Login(ctx, s.mfaUser.Email, mfaTestPassword, clientIP); err != nil {
+			t.Errorf("Login() error = %v, want the counter reset by the completed login", err)
+		}
+	})
+}
+
+func TestAuthUsecase_issueTokenPair_WithoutMFA(t *testing.T) {
+	s := newAuthTestSetup(t)
+
+	// admin yang login tanpa faktor kedua (misal lewat SSO) tidak mendapat hak admin
+	tokenPair, _, err := s.usecase.issueTokenPair(context.Background(), nil, s.mfaUser, "family-2", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.usecase.jwtService.ValidateToken(tokenPair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal := &model.Principal{Roles: claims.Roles, AMR: claims.AMR}
+	if !principal.HasRole(model.RoleAdmin) || principal.IsAdmin() {
+		t.Errorf("roles = %v, amr = %v, want the admin role without admin rights", claims.Roles, claims.AMR)
+	}
+}
+
+func TestAuthUsecase_LoginWithExternalIdentity(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	verified := &model.User{ID: 4, Email: "verified@example.com", EmailVerifiedAt: &verifiedAt}
+	unverified := &model.User{ID: 3, Email: "unverified@example.com"}
+
+	tests := []struct {
+		name          string
+		identity      *model.ExternalIdentity
+		autoProvision bool
+		wantErr       error
+		wantUserID    int64 // 0 = user baru
+	}{
+		{
+			name:       "links a verified local account",
+			identity:   &model.ExternalIdentity{Subject: "s1", Email: verified.Email, EmailVerified: true},
+			wantUserID: verified.ID,
+		},
+		{
+			name:     "refuses an unverified local account",
+			identity: &model.ExternalIdentity{Subject: "s2", Email: unverified.Email, EmailVerified: true},
+			wantErr:  utils.ErrInvalidCredentials,
+		},
+		{
+			name:     "refuses an email the provider did not verify",
+			identity: &model.ExternalIdentity{Subject: "s3", Email: verified.Email},
+			wantErr:  utils.ErrInvalidCredentials,
+		},
+		{
+			name:          "provisions a verified user",
+			identity:      &model.ExternalIdentity{Subject: "s4", Email: "new@example.com", EmailVerified: true},
+			autoProvision: true,
+		},
+		{
+			name:     "no local account without provisioning",
+			identity: &model.ExternalIdentity{Subject: "s5", Email: "new@example.com", EmailVerified: true},
+			wantErr:  utils.ErrInvalidCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAuthTestSetup(t)
+			users := newFakeUserRepository(verified, unverified)
+			identities := &fakeUserIdentityRepository{}
+			s.usecase.userRepo = users
+			s.usecase.identityRepo = identities
+			tt.identity.Provider = "mock"
+
+			_, _, err := s.usecase.LoginWithExternalIdentity(context.Background(), tt.identity, tt.autoProvision)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoginWithExternalIdentity() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(identities.identities) != 0 {
+					t.Errorf("identities = %+v, want none linked", identities.identities)
+				}
+				return
+			}
+
+			linked, err := identities.GetByProviderSubject(context.Background(), nil, "mock", tt.identity.Subject)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantUserID != 0 && linked.UserID != tt.wantUserID {
+				t.Errorf("linked user = %d, want %d", linked.UserID, tt.wantUserID)
+			}
+			user, _ := users.GetByID(context.Background(), nil, linked.UserID)
+			if user.EmailVerifiedAt == nil {
+				t.Errorf("linked user %d has no verified email", user.ID)
+			}
+		})
+	}
+}
+
+func TestAuthUsecase_LoginWithExternalIdentity_MFA(t *testing.T) {
+	ctx := context.Background()
+	s := newAuthTestSetup(t)
+	s.usecase.identityRepo = &fakeUserIdentityRepository{identities: []*model.UserIdentity{
+		{ID: 1, UserID: s.mfaUser.ID, Provider: "mock", Subject: "s-mfa", Email: s.mfaUser.Email},
+	}}
+	identity := &model.ExternalIdentity{Provider: "mock", Subject: "s-mfa", Email: s.mfaUser.Email, EmailVerified: true}
+
+	tokenPair, challenge, err := s.usecase.LoginWithExternalIdentity(ctx, identity, false)
+	if err != nil {
+		t.Fatalf("LoginWithExternalIdentity() error = %v", err)
+	}
+	if tokenPair != nil || challenge == nil || len(s.refreshTokens.tokens) != 0 {
+		t.Fatalf("LoginWithExternalIdentity() = %+v, %+v, want an MFA challenge and no session", tokenPair, challenge)
+	}
+
+	tokenPair, err = s.usecase.CompleteMFALogin(ctx, challenge.MFAToken, mfaTestRecoveryCode, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CompleteMFALogin() error = %v", err)
+	}
+	claims, err := s.usecase.jwtService.ValidateToken(tokenPair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal := (&model.Principal{Roles: claims.Roles, AMR: claims.AMR}); !principal.IsAdmin() {
+		t.Errorf("access token amr = %v, want the session verified with mfa", claims.AMR)
+	}
+}
+
+func TestAuthUsecase_deleteExpiredRevokedTokens(t *testing.T) {
+	s := newAuthTestSetup(t)
+	now := time.Now()
+	s.revokedTokens.tokens = []*model.RevokedToken{
+		{JTI: "expired", UserID: testUser.ID, ExpiresAt: now.Add(-time.Minute), RevokedAt: now.Add(-time.Hour)},
+		{JTI: "active", UserID: testUser.ID, ExpiresAt: now.Add(time.Hour), RevokedAt: now},
+	}
+
+	s.usecase.deleteExpiredRevokedTokens(context.Background())
+
+	if len(s.revokedTokens.tokens) != 1 || s.revokedTokens.tokens[0].JTI != "active" {
+		t.Errorf("revoked tokens = %+v, want only the unexpired one", s.revokedTokens.tokens)
+	}
+}
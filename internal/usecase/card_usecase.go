@@ -5,57 +5,60 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
 	"github.com/MCPutro/go-management-project/internal/model"
 	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/tracing"
 )
 
 type CardUsecase interface {
 	CreateCard(ctx context.Context, card *model.Card) error
-	GetCardsByListID(ctx context.Context, listID int64) ([]*model.Card, error)
-	GetCardByID(ctx context.Context, id int64) (*model.Card, error)
+	GetCardsByListID(ctx context.Context, listID, userID int64) ([]*model.Card, error)
+	GetCardByID(ctx context.Context, id, userID int64) (*model.Card, error)
 	UpdateCard(ctx context.Context, card *model.Card) error
-	DeleteCard(ctx context.Context, id int64, deletedBy int64) error
+	PatchCard(ctx context.Context, id int64, patch *model.CardPatch) (*model.Card, error)
+	DeleteCard(ctx context.Context, id, deletedBy, version int64) error
 }
 
 type cardUsecase struct {
-	db       *sql.DB
+	db       *database.DB
 	cardRepo repository.CardRepository
 }
 
-func NewCardUsecase(db *sql.DB, cardRepository repository.CardRepository) CardUsecase {
+func NewCardUsecase(db *database.DB, cardRepository repository.CardRepository) CardUsecase {
 	return &cardUsecase{
 		db:       db,
 		cardRepo: cardRepository,
 	}
 }
 
-func (c *cardUsecase) CreateCard(ctx context.Context, card *model.Card) error {
-	tx, err := c.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (c *cardUsecase) CreateCard(ctx context.Context, card *model.Card) (err error) {
+	ctx, span := tracing.Start(ctx, "CardUsecase.CreateCard")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "card", "CreateCard")
 
-	err = c.cardRepo.Create(ctx, tx, card)
+	err = c.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return c.cardRepo.Create(ctx, tx, card)
+	})
 	if err != nil {
 		return err
 	}
+	metrics.CardsCreated.Inc()
 
-	return tx.Commit()
+	return nil
 }
 
-func (c *cardUsecase) GetCardsByListID(ctx context.Context, listID int64) ([]*model.Card, error) {
-	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
+func (c *cardUsecase) GetCardsByListID(ctx context.Context, listID, userID int64) (_ []*model.Card, err error) {
+	ctx, span := tracing.Start(ctx, "CardUsecase.GetCardsByListID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "card", "GetCardsByListID")
 
-	cards, err := c.cardRepo.GetByListID(ctx, tx, listID)
+	var cards []*model.Card
+	err = c.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		cards, err = c.cardRepo.GetByListID(ctx, tx, listID, userID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,65 +66,92 @@ func (c *cardUsecase) GetCardsByListID(ctx context.Context, listID int64) ([]*mo
 	return cards, nil
 }
 
-func (c *cardUsecase) GetCardByID(ctx context.Context, id int64) (*model.Card, error) {
-	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
-
-	query := `SELECT id, list_id, title, content, position, created_at, created_by, updated_at, updated_by, deleted_at FROM cards WHERE id = $1 AND deleted_at IS NULL`
-	row := tx.QueryRowContext(ctx, query, id)
+func (c *cardUsecase) GetCardByID(ctx context.Context, id, userID int64) (_ *model.Card, err error) {
+	ctx, span := tracing.Start(ctx, "CardUsecase.GetCardByID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "card", "GetCardByID")
 
-	var card model.Card
-	err = row.Scan(
-		&card.ID, &card.ListID, &card.Title, &card.Content, &card.Position,
-		&card.CreatedAt, &card.CreatedBy, &card.UpdatedAt, &card.UpdatedBy, &card.DeletedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	var card *model.Card
+	err = c.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		card, err = c.cardRepo.GetByID(ctx, tx, id, userID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &card, nil
+	return card, nil
 }
 
-func (c *cardUsecase) UpdateCard(ctx context.Context, card *model.Card) error {
-	tx, err := c.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
+func (c *cardUsecase) UpdateCard(ctx context.Context, card *model.Card) (err error) {
+	ctx, span := tracing.Start(ctx, "CardUsecase.UpdateCard")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "card", "UpdateCard")
+
+	var moved bool
+	err = c.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		moved, err = c.positionChanges(ctx, tx, card.ID, card.UpdatedBy, &card.Position)
 		if err != nil {
-			tx.Rollback()
+			return err
 		}
-	}()
-
-	err = c.cardRepo.Update(ctx, tx, card)
+		return c.cardRepo.Update(ctx, tx, card)
+	})
 	if err != nil {
 		return err
 	}
+	if moved {
+		metrics.CardsMoved.Inc()
+	}
 
-	return tx.Commit()
+	return nil
 }
 
-func (c *cardUsecase) DeleteCard(ctx context.Context, id int64, deletedBy int64) error {
-	tx, err := c.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
+func (c *cardUsecase) PatchCard(ctx context.Context, id int64, patch *model.CardPatch) (_ *model.Card, err error) {
+	ctx, span := tracing.Start(ctx, "CardUsecase.PatchCard")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "card", "PatchCard")
+
+	var (
+		card  *model.Card
+		moved bool
+	)
+	err = c.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		moved, err = c.positionChanges(ctx, tx, id, patch.UpdatedBy, patch.Position)
 		if err != nil {
-			tx.Rollback()
+			return err
 		}
-	}()
+		card, err = c.cardRepo.Patch(ctx, tx, id, patch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if moved {
+		metrics.CardsMoved.Inc()
+	}
+
+	return card, nil
+}
 
-	err = c.cardRepo.Delete(ctx, tx, id, deletedBy)
+// positionChanges reports whether writing position moves the stored card; nil leaves it in place.
+// A card the user cannot access is utils.ErrNotFound, as the write itself would report.
+func (c *cardUsecase) positionChanges(ctx context.Context, tx *sql.Tx, id, userID int64, position *int) (bool, error) {
+	if position == nil {
+		return false, nil
+	}
+	stored, err := c.cardRepo.GetByID(ctx, tx, id, userID)
 	if err != nil {
-		return err
+		return false, err
 	}
+	return stored.Position != *position, nil
+}
+
+func (c *cardUsecase) DeleteCard(ctx context.Context, id, deletedBy, version int64) (err error) {
+	ctx, span := tracing.Start(ctx, "CardUsecase.DeleteCard")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "card", "DeleteCard")
 
-	return tx.Commit()
+	return c.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return c.cardRepo.Delete(ctx, tx, id, deletedBy, version)
+	})
 }
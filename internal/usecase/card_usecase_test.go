@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// updatedCard is the card a PUT by user 7 sends.
+func updatedCard(id int64, title string, position int) *model.Card {
+	card := &model.Card{ID: id, Title: title, Position: position}
+	card.UpdatedBy = 7
+	return card
+}
+
+func TestCardUsecase_CardsMoved(t *testing.T) {
+	position := func(p int) *int { return &p }
+	title := "renamed"
+
+	tests := []struct {
+		name      string
+		write     func(c CardUsecase) error
+		wantMoved bool
+		wantErr   error
+	}{
+		{
+			name: "put to another position",
+			write: func(c CardUsecase) error {
+				return c.UpdateCard(context.Background(), updatedCard(1, "card", 2))
+			},
+			wantMoved: true,
+		},
+		{
+			name: "put at the same position",
+			write: func(c CardUsecase) error {
+				return c.UpdateCard(context.Background(), updatedCard(1, title, 1))
+			},
+		},
+		{
+			name: "patch to another position",
+			write: func(c CardUsecase) error {
+				_, err := c.PatchCard(context.Background(), 1, &model.CardPatch{Position: position(3), UpdatedBy: 7})
+				return err
+			},
+			wantMoved: true,
+		},
+		{
+			name: "patch with the same position",
+			write: func(c CardUsecase) error {
+				_, err := c.PatchCard(context.Background(), 1, &model.CardPatch{Title: &title, Position: position(1), UpdatedBy: 7})
+				return err
+			},
+		},
+		{
+			name: "patch without position",
+			write: func(c CardUsecase) error {
+				_, err := c.PatchCard(context.Background(), 1, &model.CardPatch{Title: &title, UpdatedBy: 7})
+				return err
+			},
+		},
+		{
+			name: "card not found",
+			write: func(c CardUsecase) error {
+				return c.UpdateCard(context.Background(), updatedCard(2, "card", 2))
+			},
+			wantErr: utils.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := databasetest.New()
+			cards := &fakeCardRepository{cards: map[int64]*model.Card{1: {ID: 1, ListID: 1, Title: "card", Position: 1}}}
+			before := testutil.ToFloat64(metrics.CardsMoved)
+
+			err := tt.write(NewCardUsecase(db, cards))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("write error = %v, want %v", err, tt.wantErr)
+			}
+			want := 0.0
+			if tt.wantMoved {
+				want = 1
+			}
+			if moved := testutil.ToFloat64(metrics.CardsMoved) - before; moved != want {
+				t.Errorf("cards_moved_total increased by %v, want %v", moved, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,506 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+// Fake repositories keep their rows in memory and ignore tx. Methods a test does not need are
+// left to the embedded interface, so calling them panics.
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[int64]*model.User
+	// tokensInvalidatedAt menggantikan kolom users.tokens_invalidated_at
+	tokensInvalidatedAt map[int64]time.Time
+}
+
+func newFakeUserRepository(users ...*model.User) *fakeUserRepository {
+	r := &fakeUserRepository{users: make(map[int64]*model.User), tokensInvalidatedAt: make(map[int64]time.Time)}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *fakeUserRepository) GetByID(_ context.Context, _ *sql.Tx, id int64) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) GetByEmail(_ context.Context, _ *sql.Tx, email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (r *fakeUserRepository) Create(_ context.Context, _ *sql.Tx, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.ID = int64(len(r.users) + 100)
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepository) MarkEmailVerified(_ context.Context, _ *sql.Tx, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok && user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) UpdatePassword(_ context.Context, _ *sql.Tx, id int64, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		user.Password = password
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) InvalidateTokens(_ context.Context, _ *sql.Tx, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokensInvalidatedAt[id] = at
+	return nil
+}
+
+type fakeUserIdentityRepository struct {
+	repository.UserIdentityRepository
+	mu         sync.Mutex
+	identities []*model.UserIdentity
+}
+
+func (r *fakeUserIdentityRepository) Create(_ context.Context, _ *sql.Tx, identity *model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity.ID = int64(len(r.identities) + 1)
+	copied := *identity
+	r.identities = append(r.identities, &copied)
+	return nil
+}
+
+func (r *fakeUserIdentityRepository) GetByProviderSubject(_ context.Context, _ *sql.Tx, provider, subject string) (*model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (r *fakeUserIdentityRepository) UpdateLastLogin(context.Context, *sql.Tx, int64, string) error {
+	return nil
+}
+
+type fakeRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+	mu     sync.Mutex
+	tokens []*model.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepository) Create(_ context.Context, _ *sql.Tx, token *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = int64(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) GetByHashForUpdate(_ context.Context, _ *sql.Tx, tokenHash string) (*model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (r *fakeRefreshTokenRepository) Revoke(_ context.Context, _ *sql.Tx, id int64, replacedBy *int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			token.ReplacedBy = replacedBy
+		}
+	}
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(_ context.Context, _ *sql.Tx, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeAllByUserID(_ context.Context, _ *sql.Tx, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// active returns the tokens of the family that are not revoked.
+func (r *fakeRefreshTokenRepository) active(familyID string) []*model.RefreshToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var active []*model.RefreshToken
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			active = append(active, token)
+		}
+	}
+	return active
+}
+
+type fakePersonalAccessTokenRepository struct {
+	repository.PersonalAccessTokenRepository
+	mu       sync.Mutex
+	tokens   []*model.PersonalAccessToken
+	lastUsed map[int64]time.Time
+}
+
+func (r *fakePersonalAccessTokenRepository) Create(_ context.Context, _ *sql.Tx, token *model.PersonalAccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = int64(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *fakePersonalAccessTokenRepository) GetByHash(_ context.Context, _ *sql.Tx, tokenHash string) (*model.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, utils.ErrNotFound
+}
+
+func (r *fakePersonalAccessTokenRepository) UpdateLastUsed(_ context.Context, _ *sql.Tx, id int64, lastUsedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastUsed == nil {
+		r.lastUsed = make(map[int64]time.Time)
+	}
+	r.lastUsed[id] = lastUsedAt
+	return nil
+}
+
+func (r *fakePersonalAccessTokenRepository) RevokeAllByUserID(_ context.Context, _ *sql.Tx, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeUserActionTokenRepository struct {
+	repository.UserActionTokenRepository
+	mu       sync.Mutex
+	tokens   []*model.UserActionToken
+	failures map[string]int
+}
+
+func (r *fakeUserActionTokenRepository) Create(_ context.Context, _ *sql.Tx, token *model.UserActionToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *fakeUserActionTokenRepository) Consume(_ context.Context, _ *sql.Tx, id string, userID int64, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id && token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			now := time.Now()
+			token.UsedAt = &now
+			return nil
+		}
+	}
+	return utils.ErrNotFound
+}
+
+func (r *fakeUserActionTokenRepository) LockUnused(_ context.Context, _ *sql.Tx, id string, userID int64, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id && token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			return nil
+		}
+	}
+	return utils.ErrNotFound
+}
+
+func (r *fakeUserActionTokenRepository) RecordFailure(_ context.Context, _ *sql.Tx, id string, maxAttempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures == nil {
+		r.failures = make(map[string]int)
+	}
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			r.failures[id]++
+			if r.failures[id] >= maxAttempts {
+				now := time.Now()
+				token.UsedAt = &now
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserActionTokenRepository) InvalidateByUserID(_ context.Context, _ *sql.Tx, userID int64, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			now := time.Now()
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// fakeMailer keeps the sent messages instead of delivering them.
+type fakeMailer struct {
+	mu       sync.Mutex
+	messages []service.MailMessage
+}
+
+func (m *fakeMailer) Send(_ context.Context, message service.MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// fakeCardRepository keeps the cards by id; every card is accessible.
+type fakeCardRepository struct {
+	repository.CardRepository
+	mu    sync.Mutex
+	cards map[int64]*model.Card
+}
+
+func (r *fakeCardRepository) GetByID(_ context.Context, _ *sql.Tx, id, _ int64) (*model.Card, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	card, ok := r.cards[id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	copied := *card
+	return &copied, nil
+}
+
+func (r *fakeCardRepository) Update(_ context.Context, _ *sql.Tx, card *model.Card) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cards[card.ID]; !ok {
+		return utils.ErrNotFound
+	}
+	copied := *card
+	r.cards[card.ID] = &copied
+	return nil
+}
+
+func (r *fakeCardRepository) Patch(_ context.Context, _ *sql.Tx, id int64, patch *model.CardPatch) (*model.Card, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	card, ok := r.cards[id]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	if patch.Title != nil {
+		card.Title = *patch.Title
+	}
+	if patch.Position != nil {
+		card.Position = *patch.Position
+	}
+	copied := *card
+	return &copied, nil
+}
+
+// fakeRevokedTokenRepository keeps the denylist in memory.
+type fakeRevokedTokenRepository struct {
+	repository.RevokedTokenRepository
+	mu     sync.Mutex
+	tokens []*model.RevokedToken
+}
+
+func (r *fakeRevokedTokenRepository) DeleteExpired(_ context.Context, _ *sql.Tx, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var kept []*model.RevokedToken
+	for _, token := range r.tokens {
+		if !token.ExpiresAt.Before(before) {
+			kept = append(kept, token)
+		}
+	}
+	deleted := int64(len(r.tokens) - len(kept))
+	r.tokens = kept
+	return deleted, nil
+}
+
+type fakeUserMFARepository struct {
+	repository.UserMFARepository
+	mu   sync.Mutex
+	mfas map[int64]*model.UserMFA
+}
+
+func (r *fakeUserMFARepository) GetByUserIDForUpdate(_ context.Context, _ *sql.Tx, userID int64) (*model.UserMFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.mfas[userID]
+	if !ok {
+		return nil, utils.ErrNotFound
+	}
+	copied := *mfa
+	return &copied, nil
+}
+
+func (r *fakeUserMFARepository) Delete(_ context.Context, _ *sql.Tx, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.mfas, userID)
+	return nil
+}
+
+func (r *fakeUserMFARepository) UpdateLastUsedStep(_ context.Context, _ *sql.Tx, userID, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if mfa, ok := r.mfas[userID]; ok {
+		mfa.LastUsedStep = step
+	}
+	return nil
+}
+
+// fakeRecoveryCodeRepository keeps the unused code hashes per user.
+type fakeRecoveryCodeRepository struct {
+	repository.RecoveryCodeRepository
+	mu    sync.Mutex
+	codes map[int64][]string
+}
+
+func (r *fakeRecoveryCodeRepository) DeleteByUserID(_ context.Context, _ *sql.Tx, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepository) Consume(_ context.Context, _ *sql.Tx, userID int64, codeHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, hash := range r.codes[userID] {
+		if hash == codeHash {
+			r.codes[userID] = append(r.codes[userID][:i], r.codes[userID][i+1:]...)
+			return nil
+		}
+	}
+	return utils.ErrNotFound
+}
+
+type fakeAuditLogRepository struct {
+	repository.AuditLogRepository
+	mu      sync.Mutex
+	entries []*model.AuditLog
+}
+
+func (r *fakeAuditLogRepository) Create(_ context.Context, _ *sql.Tx, entry *model.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *entry
+	r.entries = append(r.entries, &copied)
+	return nil
+}
@@ -5,57 +5,60 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
 	"github.com/MCPutro/go-management-project/internal/model"
 	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/tracing"
 )
 
 type ListUsecase interface {
 	CreateList(ctx context.Context, list *model.List) error
-	GetListsByProjectID(ctx context.Context, projectID int64) ([]*model.List, error)
-	GetListByID(ctx context.Context, id int64) (*model.List, error)
+	GetListsByProjectID(ctx context.Context, projectID, userID int64) ([]*model.List, error)
+	GetListByID(ctx context.Context, id, userID int64) (*model.List, error)
 	UpdateList(ctx context.Context, list *model.List) error
-	DeleteList(ctx context.Context, id int64, deletedBy int64) error
+	PatchList(ctx context.Context, id int64, patch *model.ListPatch) (*model.List, error)
+	DeleteList(ctx context.Context, id, deletedBy, version int64) error
 }
 
 type listUsecase struct {
-	db       *sql.DB
+	db       *database.DB
 	listRepo repository.ListRepository
 }
 
-func NewListUsecase(db *sql.DB, listRepository repository.ListRepository) ListUsecase {
+func NewListUsecase(db *database.DB, listRepository repository.ListRepository) ListUsecase {
 	return &listUsecase{
 		db:       db,
 		listRepo: listRepository,
 	}
 }
 
-func (l *listUsecase) CreateList(ctx context.Context, list *model.List) error {
-	tx, err := l.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (l *listUsecase) CreateList(ctx context.Context, list *model.List) (err error) {
+	ctx, span := tracing.Start(ctx, "ListUsecase.CreateList")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "list", "CreateList")
 
-	err = l.listRepo.Create(ctx, tx, list)
+	err = l.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return l.listRepo.Create(ctx, tx, list)
+	})
 	if err != nil {
 		return err
 	}
+	metrics.ListsCreated.Inc()
 
-	return tx.Commit()
+	return nil
 }
 
-func (l *listUsecase) GetListsByProjectID(ctx context.Context, projectID int64) ([]*model.List, error) {
-	tx, err := l.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
+func (l *listUsecase) GetListsByProjectID(ctx context.Context, projectID, userID int64) (_ []*model.List, err error) {
+	ctx, span := tracing.Start(ctx, "ListUsecase.GetListsByProjectID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "list", "GetListsByProjectID")
 
-	lists, err := l.listRepo.GetByProjectID(ctx, tx, projectID)
+	var lists []*model.List
+	err = l.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		lists, err = l.listRepo.GetByProjectID(ctx, tx, projectID, userID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,65 +66,56 @@ func (l *listUsecase) GetListsByProjectID(ctx context.Context, projectID int64)
 	return lists, nil
 }
 
-func (l *listUsecase) GetListByID(ctx context.Context, id int64) (*model.List, error) {
-	tx, err := l.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
-
-	query := `SELECT id, project_id, name, position, created_at, created_by, updated_at, updated_by, deleted_at FROM lists WHERE id = $1 AND deleted_at IS NULL`
-	row := tx.QueryRowContext(ctx, query, id)
-
-	var list model.List
-	err = row.Scan(
-		&list.ID, &list.ProjectID, &list.Name, &list.Position,
-		&list.CreatedAt, &list.CreatedBy, &list.UpdatedAt, &list.UpdatedBy, &list.DeletedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil // atau return error jika diinginkan
-	}
+func (l *listUsecase) GetListByID(ctx context.Context, id, userID int64) (_ *model.List, err error) {
+	ctx, span := tracing.Start(ctx, "ListUsecase.GetListByID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "list", "GetListByID")
+
+	var list *model.List
+	err = l.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		list, err = l.listRepo.GetByID(ctx, tx, id, userID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &list, nil
+	return list, nil
 }
 
-func (l *listUsecase) UpdateList(ctx context.Context, list *model.List) error {
-	tx, err := l.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (l *listUsecase) UpdateList(ctx context.Context, list *model.List) (err error) {
+	ctx, span := tracing.Start(ctx, "ListUsecase.UpdateList")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "list", "UpdateList")
 
-	err = l.listRepo.Update(ctx, tx, list)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return l.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return l.listRepo.Update(ctx, tx, list)
+	})
 }
 
-func (l *listUsecase) DeleteList(ctx context.Context, id int64, deletedBy int64) error {
-	tx, err := l.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (l *listUsecase) PatchList(ctx context.Context, id int64, patch *model.ListPatch) (_ *model.List, err error) {
+	ctx, span := tracing.Start(ctx, "ListUsecase.PatchList")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "list", "PatchList")
 
-	err = l.listRepo.Delete(ctx, tx, id, deletedBy)
-	if err != nil {
+	var list *model.List
+	err = l.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		list, err = l.listRepo.Patch(ctx, tx, id, patch)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return list, nil
+}
+
+func (l *listUsecase) DeleteList(ctx context.Context, id, deletedBy, version int64) (err error) {
+	ctx, span := tracing.Start(ctx, "ListUsecase.DeleteList")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "list", "DeleteList")
+
+	return l.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return l.listRepo.Delete(ctx, tx, id, deletedBy, version)
+	})
 }
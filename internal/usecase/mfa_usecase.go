@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+	"github.com/MCPutro/go-management-project/internal/config"
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/service"
+	"github.com/MCPutro/go-management-project/internal/tracing"
+	"github.com/MCPutro/go-management-project/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+// MFAUsecase manages the TOTP enrollment of the signed-in user.
+type MFAUsecase interface {
+	Enroll(ctx context.Context, userID int64) (*model.MFAEnrollment, error)
+	// Confirm enables 2FA once the user enters a valid code and returns the recovery codes, which
+	// are only shown this once.
+	Confirm(ctx context.Context, userID int64, code string) ([]string, error)
+	// Disable requires the password and a current TOTP or recovery code; a wrong one is a
+	// validation error, not utils.ErrInvalidCredentials, since the caller is already signed in.
+	Disable(ctx context.Context, userID int64, password, code string) error
+}
+
+type mfaUsecase struct {
+	db               *database.DB
+	mfaConfig        *config.MfaConfig
+	userRepo         repository.UserRepository
+	mfaRepo          repository.UserMFARepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+}
+
+func NewMFAUsecase(db *database.DB, mfaConfig *config.MfaConfig, userRepository repository.UserRepository,
+	mfaRepository repository.UserMFARepository, recoveryCodeRepository repository.RecoveryCodeRepository) MFAUsecase {
+	return &mfaUsecase{
+		db:               db,
+		mfaConfig:        mfaConfig,
+		userRepo:         userRepository,
+		mfaRepo:          mfaRepository,
+		recoveryCodeRepo: recoveryCodeRepository,
+	}
+}
+
+func (m *mfaUsecase) Enroll(ctx context.Context, userID int64) (_ *model.MFAEnrollment, err error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.Enroll")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "mfa", "Enroll")
+
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+	err = m.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		user, err = m.userRepo.GetByID(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = m.mfaRepo.Save(ctx, tx, &model.UserMFA{UserID: user.ID, Secret: secret})
+		if errors.Is(err, utils.ErrInvalidInput) {
+			return apperror.Conflict("Two-factor authentication is already enabled")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: service.TOTPProvisioningURI(m.mfaConfig.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (m *mfaUsecase) Confirm(ctx context.Context, userID int64, code string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.Confirm")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "mfa", "Confirm")
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		mfa, err := m.mfaRepo.GetByUserIDForUpdate(ctx, tx, userID)
+		if errors.Is(err, utils.ErrNotFound) {
+			return apperror.Conflict("Start the two-factor enrollment first")
+		}
+		if err != nil {
+			return err
+		}
+		if mfa.EnabledAt != nil {
+			return apperror.Conflict("Two-factor authentication is already enabled")
+		}
+
+		step, ok := service.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+		if !ok {
+			return apperror.Validation("Invalid authentication code", apperror.FieldError{Field: "code", Message: "is not valid"})
+		}
+
+		err = m.mfaRepo.Enable(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		err = m.mfaRepo.UpdateLastUsedStep(ctx, tx, userID, step)
+		if err != nil {
+			return err
+		}
+
+		return m.recoveryCodeRepo.Replace(ctx, tx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (m *mfaUsecase) Disable(ctx context.Context, userID int64, password, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "MFAUsecase.Disable")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "mfa", "Disable")
+
+	return m.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		user, err := m.userRepo.GetByID(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		// GetByID tidak mengambil password, jadi ambil ulang lewat email
+		user, err = m.userRepo.GetByEmail(ctx, tx, user.Email)
+		if err != nil {
+			return err
+		}
+		// user sudah login: 401 akan dibaca client sebagai sesi habis, jadi salah password/kode adalah validation error
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+		if err != nil {
+			return apperror.Validation("Invalid password", apperror.FieldError{Field: "password", Message: "is not correct"})
+		}
+
+		mfa, err := m.mfaRepo.GetByUserIDForUpdate(ctx, tx, userID)
+		if errors.Is(err, utils.ErrNotFound) || (err == nil && mfa.EnabledAt == nil) {
+			return apperror.Conflict("Two-factor authentication is not enabled")
+		}
+		if err != nil {
+			return err
+		}
+
+		err = verifySecondFactor(ctx, tx, m.mfaRepo, m.recoveryCodeRepo, mfa, code)
+		if errors.Is(err, utils.ErrInvalidCredentials) {
+			return apperror.Validation("Invalid authentication code", apperror.FieldError{Field: "code", Message: "is not valid"})
+		}
+		if err != nil {
+			return err
+		}
+
+		err = m.recoveryCodeRepo.DeleteByUserID(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		return m.mfaRepo.Delete(ctx, tx, userID)
+	})
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code and burns what it accepted.
+func verifySecondFactor(ctx context.Context, tx *sql.Tx, mfaRepo repository.UserMFARepository,
+	recoveryCodeRepo repository.RecoveryCodeRepository, mfa *model.UserMFA, code string) error {
+	code = strings.TrimSpace(code)
+
+	if step, ok := service.ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep); ok {
+		return mfaRepo.UpdateLastUsedStep(ctx, tx, mfa.UserID, step)
+	}
+
+	err := recoveryCodeRepo.Consume(ctx, tx, mfa.UserID, utils.HashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("%w: invalid authentication code", utils.ErrInvalidCredentials)
+	}
+	return err
+}
+
+// generateRecoveryCodes returns codes formatted as xxxxx-xxxxx together with their hashes.
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for i := 0; i < n; i++ {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		value := strings.ToLower(encoding.EncodeToString(raw))[:10]
+
+		codes = append(codes, value[:5]+"-"+value[5:])
+		hashes = append(hashes, utils.HashToken(value))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
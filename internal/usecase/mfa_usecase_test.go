@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/MCPutro/go-management-project/internal/apperror"
+)
+
+func TestMFAUsecase_Disable(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		code       string
+		wantStatus int // 0 = berhasil
+		wantField  string
+	}{
+		{name: "disables with password and recovery code", password: mfaTestPassword, code: mfaTestRecoveryCode},
+		{name: "wrong password", password: "wrong-password", code: mfaTestRecoveryCode, wantStatus: http.StatusBadRequest, wantField: "password"},
+		{name: "wrong code", password: mfaTestPassword, code: "00000-00000", wantStatus: http.StatusBadRequest, wantField: "code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAuthTestSetup(t)
+			mfaRepo := s.usecase.mfaRepo.(*fakeUserMFARepository)
+			m := NewMFAUsecase(s.usecase.db, s.usecase.mfaConfig, s.usecase.userRepo, mfaRepo, s.recoveryCodes)
+
+			err := m.Disable(context.Background(), s.mfaUser.ID, tt.password, tt.code)
+			_, enabled := mfaRepo.mfas[s.mfaUser.ID]
+			if tt.wantStatus == 0 {
+				if err != nil || enabled {
+					t.Errorf("Disable() error = %v, mfa enabled = %v, want disabled", err, enabled)
+				}
+				return
+			}
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Status() != tt.wantStatus {
+				t.Fatalf("Disable() error = %v, want status %d", err, tt.wantStatus)
+			}
+			if len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.wantField {
+				t.Errorf("Disable() fields = %+v, want %s", appErr.Fields, tt.wantField)
+			}
+			if !enabled {
+				t.Error("Disable() with a wrong factor disabled mfa")
+			}
+		})
+	}
+}
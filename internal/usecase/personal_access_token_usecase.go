@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/tracing"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+// PersonalAccessTokenPrefix marks opaque personal access tokens so the auth middleware can tell them apart from JWTs.
+const PersonalAccessTokenPrefix = "gmp_"
+
+type PersonalAccessTokenUsecase interface {
+	// CreateToken returns the stored token and the plaintext value, which is never retrievable again.
+	CreateToken(ctx context.Context, token *model.PersonalAccessToken) (string, error)
+	GetTokensByUserID(ctx context.Context, userID int64) ([]*model.PersonalAccessToken, error)
+	RevokeToken(ctx context.Context, id, userID int64) error
+	Authenticate(ctx context.Context, plaintext string) (*model.Principal, error)
+	// RunLastUsedFlush writes the last-used times collected by Authenticate until ctx is done.
+	RunLastUsedFlush(ctx context.Context)
+}
+
+// lastUsedFlushInterval is how stale last_used_at may get; it is only shown to the owner.
+const lastUsedFlushInterval = time.Minute
+
+type personalAccessTokenUsecase struct {
+	db        *database.DB
+	tokenRepo repository.PersonalAccessTokenRepository
+	userRepo  repository.UserRepository
+
+	// lastUsed menampung waktu pemakaian per token id sampai ditulis oleh RunLastUsedFlush
+	mu       sync.Mutex
+	lastUsed map[int64]time.Time
+}
+
+func NewPersonalAccessTokenUsecase(db *database.DB, tokenRepository repository.PersonalAccessTokenRepository, userRepository repository.UserRepository) PersonalAccessTokenUsecase {
+	return &personalAccessTokenUsecase{
+		db:        db,
+		tokenRepo: tokenRepository,
+		userRepo:  userRepository,
+		lastUsed:  make(map[int64]time.Time),
+	}
+}
+
+func (p *personalAccessTokenUsecase) CreateToken(ctx context.Context, token *model.PersonalAccessToken) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "PersonalAccessTokenUsecase.CreateToken")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "personal_access_token", "CreateToken")
+
+	for _, scope := range token.Scopes {
+		if !isPersonalAccessTokenScope(scope) {
+			return "", fmt.Errorf("%w: unknown scope %q", utils.ErrInvalidInput, scope)
+		}
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	plaintext := PersonalAccessTokenPrefix + secret
+	token.TokenPrefix = plaintext[:len(PersonalAccessTokenPrefix)+8]
+	token.TokenHash = utils.HashToken(plaintext)
+
+	err = p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return p.tokenRepo.Create(ctx, tx, token)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+func (p *personalAccessTokenUsecase) GetTokensByUserID(ctx context.Context, userID int64) (_ []*model.PersonalAccessToken, err error) {
+	ctx, span := tracing.Start(ctx, "PersonalAccessTokenUsecase.GetTokensByUserID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "personal_access_token", "GetTokensByUserID")
+
+	var tokens []*model.PersonalAccessToken
+	err = p.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		tokens, err = p.tokenRepo.GetByUserID(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (p *personalAccessTokenUsecase) RevokeToken(ctx context.Context, id, userID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "PersonalAccessTokenUsecase.RevokeToken")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "personal_access_token", "RevokeToken")
+
+	return p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return p.tokenRepo.Revoke(ctx, tx, id, userID)
+	})
+}
+
+func (p *personalAccessTokenUsecase) Authenticate(ctx context.Context, plaintext string) (_ *model.Principal, err error) {
+	ctx, span := tracing.Start(ctx, "PersonalAccessTokenUsecase.Authenticate")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "personal_access_token", "Authenticate")
+
+	if !strings.HasPrefix(plaintext, PersonalAccessTokenPrefix) {
+		return nil, utils.ErrInvalidToken
+	}
+
+	now := time.Now()
+	var (
+		token *model.PersonalAccessToken
+		user  *model.User
+	)
+	// token yang baru dicabut tidak boleh lolos karena replica tertinggal
+	err = p.db.RunInTx(database.WithPrimary(ctx), &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		token, err = p.tokenRepo.GetByHash(ctx, tx, utils.HashToken(plaintext))
+		if err != nil {
+			return err
+		}
+		if token.RevokedAt != nil || (token.ExpiresAt != nil && now.After(*token.ExpiresAt)) {
+			return utils.ErrInvalidToken
+		}
+
+		user, err = p.userRepo.GetByID(ctx, tx, token.UserID)
+		return err
+	})
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, utils.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.lastUsed[token.ID] = now
+	p.mu.Unlock()
+
+	principal := &model.Principal{
+		UserID:     user.ID,
+		Email:      user.Email,
+		Roles:      user.Roles,
+		TokenID:    fmt.Sprintf("pat-%d", token.ID),
+		AuthMethod: model.AuthMethodPersonalAccessToken,
+		Scopes:     token.Scopes,
+	}
+	if token.ExpiresAt != nil {
+		principal.TokenExpiresAt = *token.ExpiresAt
+	}
+	if principal.Scopes == nil {
+		principal.Scopes = []string{}
+	}
+
+	return principal, nil
+}
+
+func (p *personalAccessTokenUsecase) RunLastUsedFlush(ctx context.Context) {
+	ticker := time.NewTicker(lastUsedFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx sudah dibatalkan saat shutdown, sisa antrean tetap ditulis sebelum database ditutup
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			p.flushLastUsed(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			p.flushLastUsed(ctx)
+		}
+	}
+}
+
+func (p *personalAccessTokenUsecase) flushLastUsed(ctx context.Context) {
+	p.mu.Lock()
+	pending := p.lastUsed
+	p.lastUsed = make(map[int64]time.Time)
+	p.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	err := p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		for id, lastUsedAt := range pending {
+			if err := p.tokenRepo.UpdateLastUsed(ctx, tx, id, lastUsedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// hanya informasi untuk pemilik token, batch berikutnya menimpa nilainya
+		slog.WarnContext(ctx, "failed to update personal access token last used", slog.Int("tokens", len(pending)), slog.Any("error", err))
+	}
+}
+
+func isPersonalAccessTokenScope(scope string) bool {
+	for _, s := range model.PersonalAccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MCPutro/go-management-project/internal/config/database/databasetest"
+	"github.com/MCPutro/go-management-project/internal/model"
+	"github.com/MCPutro/go-management-project/utils"
+)
+
+func TestPersonalAccessTokenUsecase_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	owner := &model.User{ID: 1, Email: "owner@example.com", Roles: []string{model.RoleAdmin}}
+
+	tests := []struct {
+		name      string
+		plaintext string
+		token     *model.PersonalAccessToken
+		wantErr   error
+	}{
+		{
+			name:  "valid token",
+			token: &model.PersonalAccessToken{UserID: owner.ID, Scopes: []string{model.ScopeRead}},
+		},
+		{
+			name:      "missing prefix",
+			plaintext: "not-a-token",
+			wantErr:   utils.ErrInvalidToken,
+		},
+		{
+			name:      "unknown token",
+			plaintext: PersonalAccessTokenPrefix + "unknown",
+			wantErr:   utils.ErrInvalidToken,
+		},
+		{
+			name:    "revoked token",
+			token:   &model.PersonalAccessToken{UserID: owner.ID, RevokedAt: &past},
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name:    "expired token",
+			token:   &model.PersonalAccessToken{UserID: owner.ID, ExpiresAt: &past},
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name:    "deleted owner",
+			token:   &model.PersonalAccessToken{UserID: 99},
+			wantErr: utils.ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, stats := databasetest.New()
+			tokens := &fakePersonalAccessTokenRepository{}
+			p := NewPersonalAccessTokenUsecase(db, tokens, newFakeUserRepository(owner)).(*personalAccessTokenUsecase)
+
+			plaintext := tt.plaintext
+			if tt.token != nil {
+				var err error
+				plaintext, err = p.CreateToken(ctx, tt.token)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			principal, err := p.Authenticate(ctx, plaintext)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if principal.UserID != owner.ID || principal.AuthMethod != model.AuthMethodPersonalAccessToken ||
+				!principal.HasRole(model.RoleAdmin) || !principal.HasScope(model.ScopeRead) || principal.HasScope(model.ScopeWrite) {
+				t.Errorf("Authenticate() = %+v", principal)
+			}
+			// CreateToken menulis, Authenticate hanya membaca
+			if stats.ReadOnly() != 1 || stats.Commits() != 2 {
+				t.Errorf("read-only transactions = %d, commits = %d, want 1 and 2", stats.ReadOnly(), stats.Commits())
+			}
+			if len(tokens.lastUsed) != 0 {
+				t.Errorf("last used written during Authenticate: %v", tokens.lastUsed)
+			}
+
+			p.flushLastUsed(ctx)
+			if _, ok := tokens.lastUsed[tt.token.ID]; !ok {
+				t.Errorf("last used after flush = %v, want token %d", tokens.lastUsed, tt.token.ID)
+			}
+		})
+	}
+}
+
+func TestPersonalAccessTokenUsecase_RunLastUsedFlush(t *testing.T) {
+	db, _ := databasetest.New()
+	tokens := &fakePersonalAccessTokenRepository{}
+	p := NewPersonalAccessTokenUsecase(db, tokens, newFakeUserRepository()).(*personalAccessTokenUsecase)
+	p.lastUsed[7] = time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// worker yang dihentikan tetap menulis antrean yang tersisa
+	p.RunLastUsedFlush(ctx)
+
+	if _, ok := tokens.lastUsed[7]; !ok {
+		t.Errorf("last used after stop = %v, want token 7", tokens.lastUsed)
+	}
+}
@@ -4,24 +4,28 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
 	"github.com/MCPutro/go-management-project/internal/model"
 	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/tracing"
 )
 
 type ProjectUsecase interface {
 	CreateProject(ctx context.Context, project *model.Project) error
-	GetProjectByID(ctx context.Context, id int64) (*model.Project, error)
+	GetProjectByID(ctx context.Context, id, userID int64) (*model.Project, error)
 	UpdateProject(ctx context.Context, project *model.Project) error
-	DeleteProject(ctx context.Context, id int64, deletedBy int64) error
+	PatchProject(ctx context.Context, id int64, patch *model.ProjectPatch) (*model.Project, error)
+	DeleteProject(ctx context.Context, id, deletedBy, version int64) error
 }
 
 type projectUsecase struct {
-	db          *sql.DB
+	db          *database.DB
 	projectRepo repository.ProjectRepository
 	listRepo    repository.ListRepository
 }
 
-func NewProjectUsecase(db *sql.DB, projectRepository repository.ProjectRepository, listRepository repository.ListRepository) ProjectUsecase {
+func NewProjectUsecase(db *database.DB, projectRepository repository.ProjectRepository, listRepository repository.ListRepository) ProjectUsecase {
 	return &projectUsecase{
 		db:          db,
 		projectRepo: projectRepository,
@@ -29,33 +33,32 @@ func NewProjectUsecase(db *sql.DB, projectRepository repository.ProjectRepositor
 	}
 }
 
-func (p *projectUsecase) CreateProject(ctx context.Context, project *model.Project) error {
-	tx, err := p.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (p *projectUsecase) CreateProject(ctx context.Context, project *model.Project) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectUsecase.CreateProject")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "project", "CreateProject")
 
-	err = p.projectRepo.Create(ctx, tx, project)
+	err = p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return p.projectRepo.Create(ctx, tx, project)
+	})
 	if err != nil {
 		return err
 	}
+	metrics.ProjectsCreated.Inc()
 
-	return tx.Commit()
+	return nil
 }
 
-func (p *projectUsecase) GetProjectByID(ctx context.Context, id int64) (*model.Project, error) {
-	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
+func (p *projectUsecase) GetProjectByID(ctx context.Context, id, userID int64) (_ *model.Project, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectUsecase.GetProjectByID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "project", "GetProjectByID")
 
-	project, err := p.projectRepo.GetByID(ctx, tx, id)
+	var project *model.Project
+	err = p.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		project, err = p.projectRepo.GetByID(ctx, tx, id, userID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,77 +66,74 @@ func (p *projectUsecase) GetProjectByID(ctx context.Context, id int64) (*model.P
 	return project, nil
 }
 
-func (p *projectUsecase) UpdateProject(ctx context.Context, project *model.Project) error {
-	tx, err := p.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	err = p.projectRepo.Update(ctx, tx, project)
-	if err != nil {
-		return err
-	}
+func (p *projectUsecase) UpdateProject(ctx context.Context, project *model.Project) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectUsecase.UpdateProject")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "project", "UpdateProject")
 
-	return tx.Commit()
+	return p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return p.projectRepo.Update(ctx, tx, project)
+	})
 }
 
-func (p *projectUsecase) DeleteProject(ctx context.Context, id int64, deletedBy int64) error {
-	tx, err := p.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (p *projectUsecase) PatchProject(ctx context.Context, id int64, patch *model.ProjectPatch) (_ *model.Project, err error) {
+	ctx, span := tracing.Start(ctx, "ProjectUsecase.PatchProject")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "project", "PatchProject")
 
-	err = p.projectRepo.Delete(ctx, tx, id, deletedBy)
-	if err != nil {
+	var project *model.Project
+	err = p.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		project, err = p.projectRepo.Patch(ctx, tx, id, patch)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return project, nil
+}
+
+func (p *projectUsecase) DeleteProject(ctx context.Context, id, deletedBy, version int64) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectUsecase.DeleteProject")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "project", "DeleteProject")
+
+	return p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return p.projectRepo.Delete(ctx, tx, id, deletedBy, version)
+	})
 }
 
 // Method baru:
-func (p *projectUsecase) CreateProjectWithDefaultList(ctx context.Context, project *model.Project, defaultListName string) error {
-	tx, err := p.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
+func (p *projectUsecase) CreateProjectWithDefaultList(ctx context.Context, project *model.Project, defaultListName string) (err error) {
+	ctx, span := tracing.Start(ctx, "ProjectUsecase.CreateProjectWithDefaultList")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "project", "CreateProjectWithDefaultList")
+
+	err = p.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		// 1. Simpan project
+		err := p.projectRepo.Create(ctx, tx, project)
 		if err != nil {
-			tx.Rollback()
+			return err
 		}
-	}()
 
-	// 1. Simpan project
-	err = p.projectRepo.Create(ctx, tx, project)
-	if err != nil {
-		return err
-	}
-
-	// 2. Buat list default
-	defaultList := &model.List{
-		ProjectID: project.ID,
-		Name:      defaultListName,
-		Position:  1,
-		Audit: model.Audit{
-			CreatedBy: project.CreatedBy,
-			UpdatedBy: project.UpdatedBy,
-		},
-	}
+		// 2. Buat list default
+		defaultList := &model.List{
+			ProjectID: project.ID,
+			Name:      defaultListName,
+			Position:  1,
+			Audit: model.Audit{
+				CreatedBy: project.CreatedBy,
+				UpdatedBy: project.UpdatedBy,
+			},
+		}
 
-	err = p.listRepo.Create(ctx, tx, defaultList)
+		return p.listRepo.Create(ctx, tx, defaultList)
+	})
 	if err != nil {
 		return err
 	}
+	metrics.ProjectsCreated.Inc()
+	metrics.ListsCreated.Inc()
 
-	return tx.Commit()
+	return nil
 }
@@ -4,53 +4,50 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/MCPutro/go-management-project/internal/config/database"
+	"github.com/MCPutro/go-management-project/internal/metrics"
 	"github.com/MCPutro/go-management-project/internal/model"
 	"github.com/MCPutro/go-management-project/internal/repository"
+	"github.com/MCPutro/go-management-project/internal/tracing"
 )
 
 type UserUsecase interface {
 	CreateUser(ctx context.Context, user *model.User) error
 	GetUserByID(ctx context.Context, id int64) (*model.User, error)
 	UpdateUser(ctx context.Context, user *model.User) error
-	DeleteUser(ctx context.Context, id int64, deletedBy int64) error
+	PatchUser(ctx context.Context, id int64, patch *model.UserPatch) (*model.User, error)
+	DeleteUser(ctx context.Context, id, deletedBy, version int64) error
 }
 
 type userUsecase struct {
-	db       *sql.DB
+	db       *database.DB
 	userRepo repository.UserRepository
 }
 
-func NewUserUsecase(db *sql.DB, userRepository repository.UserRepository) UserUsecase {
+func NewUserUsecase(db *database.DB, userRepository repository.UserRepository) UserUsecase {
 	return &userUsecase{db: db, userRepo: userRepository}
 }
 
-func (u *userUsecase) CreateUser(ctx context.Context, user *model.User) error {
-	tx, err := u.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	err = u.userRepo.Create(ctx, tx, user)
-	if err != nil {
-		return err
-	}
+func (u *userUsecase) CreateUser(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.CreateUser")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "user", "CreateUser")
 
-	return tx.Commit()
+	return u.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return u.userRepo.Create(ctx, tx, user)
+	})
 }
 
-func (u *userUsecase) GetUserByID(ctx context.Context, id int64) (*model.User, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback() // tidak perlu commit untuk read-only
+func (u *userUsecase) GetUserByID(ctx context.Context, id int64) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetUserByID")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "user", "GetUserByID")
 
-	user, err := u.userRepo.GetByID(ctx, tx, id)
+	var user *model.User
+	err = u.db.RunInTx(ctx, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) (err error) {
+		user, err = u.userRepo.GetByID(ctx, tx, id)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -58,40 +55,39 @@ func (u *userUsecase) GetUserByID(ctx context.Context, id int64) (*model.User, e
 	return user, nil
 }
 
-func (u *userUsecase) UpdateUser(ctx context.Context, user *model.User) error {
-	tx, err := u.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	err = u.userRepo.Update(ctx, tx, user)
-	if err != nil {
-		return err
-	}
+func (u *userUsecase) UpdateUser(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.UpdateUser")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "user", "UpdateUser")
 
-	return tx.Commit()
+	return u.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return u.userRepo.Update(ctx, tx, user)
+	})
 }
 
-func (u *userUsecase) DeleteUser(ctx context.Context, id int64, deletedBy int64) error {
-	tx, err := u.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (u *userUsecase) PatchUser(ctx context.Context, id int64, patch *model.UserPatch) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.PatchUser")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "user", "PatchUser")
 
-	err = u.userRepo.Delete(ctx, tx, id, deletedBy)
-	if err != nil {
+	var user *model.User
+	err = u.db.RunInTx(ctx, nil, func(tx *sql.Tx) (err error) {
+		user, err = u.userRepo.Patch(ctx, tx, id, patch)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return user, nil
+}
+
+func (u *userUsecase) DeleteUser(ctx context.Context, id, deletedBy, version int64) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.DeleteUser")
+	defer tracing.End(span, &err)
+	ctx = metrics.WithTransaction(ctx, "user", "DeleteUser")
+
+	return u.db.RunInTx(ctx, nil, func(tx *sql.Tx) error {
+		return u.userRepo.Delete(ctx, tx, id, deletedBy, version)
+	})
 }
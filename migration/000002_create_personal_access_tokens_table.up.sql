@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS personal_access_tokens
+(
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT       NOT NULL REFERENCES users (id),
+    name         VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16)  NOT NULL,
+    token_hash   VARCHAR(64)  NOT NULL UNIQUE,
+    scopes       TEXT[]       NOT NULL DEFAULT '{}',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens (user_id);
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS user_action_tokens
+(
+    id         UUID PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id),
+    purpose    VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_action_tokens_user_id_purpose ON user_action_tokens (user_id, purpose);
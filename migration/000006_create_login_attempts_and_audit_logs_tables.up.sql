@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key             VARCHAR(320) PRIMARY KEY,
+    failures        INT         NOT NULL DEFAULT 0,
+    first_failed_at TIMESTAMPTZ NOT NULL,
+    locked_until    TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS audit_logs
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT REFERENCES users (id),
+    action     VARCHAR(64) NOT NULL,
+    ip_address VARCHAR(64),
+    detail     TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_created_at ON audit_logs (action, created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE cards DROP COLUMN IF EXISTS version;
+ALTER TABLE lists DROP COLUMN IF EXISTS version;
+ALTER TABLE projects DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
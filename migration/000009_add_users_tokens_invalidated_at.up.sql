@@ -0,0 +1,2 @@
+-- access token (JWT) yang diterbitkan sebelum waktu ini ditolak, diisi saat password di-reset
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_invalidated_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS mfa_verified;
+ALTER TABLE user_action_tokens DROP COLUMN IF EXISTS failed_attempts;
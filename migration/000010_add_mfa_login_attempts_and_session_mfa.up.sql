@@ -0,0 +1,4 @@
+-- percobaan kode yang gagal per token login MFA, token dibatalkan saat mencapai batas
+ALTER TABLE user_action_tokens ADD COLUMN IF NOT EXISTS failed_attempts INT NOT NULL DEFAULT 0;
+-- sesi yang login dengan faktor kedua tetap dianggap terverifikasi setelah refresh
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,10 @@
+-- token bucket rate limit yang dipakai bersama oleh semua instance (RateLimit.Store: database)
+CREATE TABLE IF NOT EXISTS rate_limit_buckets
+(
+    key        VARCHAR(320) PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL,
+    full_at    TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_full_at ON rate_limit_buckets (full_at);
@@ -0,0 +1,8 @@
+// Package migration embeds the golang-migrate files, so the binary knows the schema version it
+// expects without the directory being deployed next to it.
+package migration
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
@@ -3,5 +3,11 @@ package utils
 import "errors"
 
 var (
-	ErrNotFound = errors.New("record not found")
+	ErrNotFound           = errors.New("record not found")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrTokenReused        = errors.New("refresh token reuse detected")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts")
+	ErrVersionConflict    = errors.New("record was modified by another request")
 )
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestGenerateRandomToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		wantLen int
+	}{
+		{name: "32 bytes", n: 32, wantLen: 43},
+		{name: "16 bytes", n: 16, wantLen: 22},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateRandomToken(tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.wantLen {
+				t.Errorf("GenerateRandomToken() = %q, want %d characters", got, tt.wantLen)
+			}
+			decoded, err := base64.RawURLEncoding.DecodeString(got)
+			if err != nil || len(decoded) != tt.n {
+				t.Errorf("GenerateRandomToken() = %q is not %d bytes of unpadded base64url", got, tt.n)
+			}
+		})
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		token, err := GenerateRandomToken(32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[token] {
+			t.Fatalf("GenerateRandomToken() returned %q twice", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{name: "empty", token: "", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{name: "abc", token: "abc", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashToken(tt.token); got != tt.want {
+				t.Errorf("HashToken(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}